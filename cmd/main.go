@@ -1,9 +1,17 @@
 package main
 
 import (
-	"codetest/internal/app"
+	"codetest/internal/cli"
 	"codetest/internal/config"
+	"codetest/internal/logger"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
 
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -28,12 +36,33 @@ import (
 // @name                        Authorization
 // @description Enter the token with the `Bearer ` prefix, e.g. "Bearer abcde12345"
 func main() {
-	cfg := config.NewAppConfig()
-
-	server, err := app.NewServerApp(cfg)
+	cfg, err := config.NewAppConfig()
 	if err != nil {
-		log.Fatalf("Failed to create server app: %v", err)
+		log.Fatal(err)
+	}
+
+	// serve is the default command, so "app --demo" is "app serve --demo".
+	args := os.Args[1:]
+	if len(args) == 0 || (strings.HasPrefix(args[0], "-") && !slices.Contains([]string{"-h", "-help", "--help"}, args[0])) {
+		args = append([]string{"serve"}, args...)
+	}
+
+	// Other commands print their results on stdout, logs go aside.
+	logOutput := os.Stdout
+	if args[0] != "serve" {
+		logOutput = os.Stderr
+	}
+
+	if err := logger.Setup(logOutput, cfg.LOG_LEVEL, cfg.LOG_FORMAT); err != nil {
+		log.Fatalf("Failed to set up logger: %v", err)
 	}
 
-	server.Run()
+	if err := cli.Run(context.Background(), cfg, args, os.Stdout); err != nil {
+		if errors.Is(err, cli.ErrUsage) {
+			fmt.Fprintln(os.Stderr, err)
+		} else {
+			slog.Error("Command failed", logger.Error(err))
+		}
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,17 @@
+// Package migrations embeds the goose SQL migrations so the binary can apply
+// them without the files being shipped next to it. Each database driver has
+// its own set, in the directory named after the driver.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql mysql/*.sql
+var FS embed.FS
+
+// For returns the migrations of driver, one of the DB_DRIVER values.
+func For(driver string) (fs.FS, error) {
+	return fs.Sub(FS, driver)
+}
@@ -0,0 +1,83 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/glebarez/go-sqlite"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+)
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		driver     string
+		sqlDriver  string
+		dataSource string
+		dialect    goose.Dialect
+	}{
+		{driver: "postgres", sqlDriver: "pgx", dataSource: "postgres://localhost/unused", dialect: goose.DialectPostgres},
+		{driver: "mysql", sqlDriver: "mysql", dataSource: "root@tcp(localhost:3306)/unused", dialect: goose.DialectMySQL},
+		{driver: "sqlite", sqlDriver: "sqlite", dataSource: ":memory:", dialect: goose.DialectSQLite3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			files, err := filepath.Glob(filepath.Join(tt.driver, "*.sql"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fsys, err := For(tt.driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The provider does not connect until a migration runs.
+			db, err := sql.Open(tt.sqlDriver, tt.dataSource)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+
+			provider, err := goose.NewProvider(tt.dialect, db, fsys)
+			if err != nil {
+				t.Fatalf("expected embedded migrations to load, got %v", err)
+			}
+
+			if sources := provider.ListSources(); len(sources) != len(files) {
+				t.Errorf("expected %d embedded migrations, got %d", len(files), len(sources))
+			}
+		})
+	}
+}
+
+func TestSQLiteUpAndDown(t *testing.T) {
+	fsys, err := For("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, db, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := provider.Up(ctx); err != nil {
+		t.Fatalf("expected migrations to apply, got %v", err)
+	}
+	if _, err := provider.DownTo(ctx, 0); err != nil {
+		t.Fatalf("expected migrations to roll back, got %v", err)
+	}
+}
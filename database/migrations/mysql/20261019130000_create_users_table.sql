@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS users (
+  id CHAR(36) NOT NULL PRIMARY KEY,
+  name VARCHAR(255) NOT NULL,
+  email VARCHAR(255) NOT NULL,
+  password VARCHAR(255) NOT NULL,
+  role VARCHAR(32) NOT NULL DEFAULT 'user',
+  version INT NOT NULL DEFAULT 1,
+  created_at DATETIME(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at DATETIME(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  deleted_at DATETIME(6) NULL,
+  disabled_at DATETIME(6) NULL,
+  -- MySQL has no partial indexes, NULL for soft deleted users keeps their
+  -- email out of the unique index so it can be registered again.
+  active_email VARCHAR(255) AS (IF(deleted_at IS NULL, email, NULL)) STORED,
+  UNIQUE INDEX idx_users_email_active (active_email),
+  INDEX idx_users_deleted_at (deleted_at)
+) DEFAULT CHARSET = utf8mb4;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS users;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS jobs (
+  id CHAR(36) NOT NULL PRIMARY KEY,
+  type VARCHAR(64) NOT NULL,
+  status VARCHAR(32) NOT NULL DEFAULT 'queued',
+  payload JSON NULL,
+  result JSON NULL,
+  error TEXT NULL,
+  progress INT NOT NULL DEFAULT 0,
+  total INT NOT NULL DEFAULT 0,
+  input_path VARCHAR(1024) NULL,
+  artifact_path VARCHAR(1024) NULL,
+  cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+  attempts INT NOT NULL DEFAULT 0,
+  created_by VARCHAR(64) NULL,
+  created_at DATETIME(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at DATETIME(6) NULL DEFAULT CURRENT_TIMESTAMP(6),
+  started_at DATETIME(6) NULL,
+  finished_at DATETIME(6) NULL,
+  INDEX idx_jobs_status_created_at (status, created_at)
+) DEFAULT CHARSET = utf8mb4;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS jobs;
+-- +goose StatementEnd
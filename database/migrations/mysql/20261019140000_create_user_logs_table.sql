@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_logs (
+  id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  user_id VARCHAR(64) NOT NULL,
+  event VARCHAR(64) NOT NULL,
+  data JSON NULL,
+  request_id VARCHAR(128) NULL,
+  trace_context JSON NULL,
+  created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  INDEX idx_user_logs_created_at (created_at),
+  INDEX idx_user_logs_user_id (user_id, created_at),
+  INDEX idx_user_logs_event (event, created_at)
+) DEFAULT CHARSET = utf8mb4;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_logs;
+-- +goose StatementEnd
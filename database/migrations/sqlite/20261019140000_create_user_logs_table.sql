@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_logs (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  user_id VARCHAR(64) NOT NULL,
+  event VARCHAR(64) NOT NULL,
+  data TEXT NULL,
+  request_id VARCHAR(128) NULL,
+  trace_context TEXT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_logs_created_at ON user_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_user_logs_user_id ON user_logs(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_user_logs_event ON user_logs(event, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_logs;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+const (
+	HealthStatusUp           = "up"
+	HealthStatusDown         = "down"
+	HealthStatusShuttingDown = "shutting_down"
+)
+
+type HealthComponent struct {
+	Status    string  `json:"status" example:"up"`
+	LatencyMs float64 `json:"latency_ms" example:"1.25"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status     string                     `json:"status" example:"up"`
+	Components map[string]HealthComponent `json:"components"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+}
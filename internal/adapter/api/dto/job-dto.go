@@ -0,0 +1,19 @@
+package dto
+
+type JobIDParam struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type PurgeUsersRequest struct {
+	OlderThanDays int `json:"older_than_days" binding:"required,min=1,max=3650"`
+}
+
+type UserImportJobPayload struct {
+	FileName string `json:"file_name"`
+	Format   string `json:"format"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+type UserExportJobPayload struct {
+	RawQuery string `json:"raw_query"`
+}
@@ -1,5 +1,14 @@
 package dto
 
+import (
+	"mime/multipart"
+	"net/url"
+	"slices"
+	"strings"
+
+	"codetest/internal/query"
+)
+
 type CreateUserRequest struct {
 	Name            string `json:"name" binding:"required,max=250"`
 	Email           string `json:"email" binding:"required,email,max=250"`
@@ -7,22 +16,151 @@ type CreateUserRequest struct {
 	ConfirmPassword string `json:"confirm_password" binding:"required,min=6,max=250"`
 }
 
+const (
+	DeletedInclude = "include"
+	DeletedOnly    = "only"
+)
+
 type QueryUserRequest struct {
 	Search   string `form:"search" binding:"omitempty,max=50"`
+	Deleted  string `form:"deleted" binding:"omitempty,oneof=include only"`
+	Sort     string `form:"sort" binding:"omitempty,max=100" example:"-created_at,name"`
 	Page     int    `form:"page" binding:"omitempty,min=1"`
 	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+
+	Query *query.Query `form:"-" swaggerignore:"true"`
+}
+
+// UserQuerySchema whitelists the fields accepted by filter[...] and sort on
+// the users list.
+var UserQuerySchema = query.Schema{
+	"id":         {Column: "id", Type: query.TypeUUID, Operators: []query.Operator{query.OpEq, query.OpIn}},
+	"name":       {Column: "name", Type: query.TypeString, Operators: query.StringOperators, Sortable: true},
+	"email":      {Column: "email", Type: query.TypeString, Operators: query.StringOperators, Sortable: true},
+	"created_at": {Column: "created_at", Type: query.TypeTime, Operators: query.TimeOperators, Sortable: true},
+	"updated_at": {Column: "updated_at", Type: query.TypeTime, Operators: query.TimeOperators, Sortable: true},
+	"deleted_at": {Column: "deleted_at", Type: query.TypeTime, Operators: query.TimeOperators, Sortable: true},
 }
 
+// UpdateUserRequest is the full representation accepted by PUT and the
+// result a PATCH must produce. An empty password keeps the current one.
 type UpdateUserRequest struct {
-	Name     string `json:"name" binding:"omitempty,max=50"`
-	Email    string `json:"email" binding:"omitempty,max=50,email"`
-	Password string `json:"password" binding:"omitempty,min=6,max=250"`
+	Name     string `json:"name" binding:"required,max=250"`
+	Email    string `json:"email" binding:"required,email,max=250"`
+	Password string `json:"password,omitempty" binding:"omitempty,min=6,max=250"`
+}
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+type ImportUserRequest struct {
+	File   *multipart.FileHeader `form:"file" binding:"required" swaggerignore:"true"`
+	Format string                `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	DryRun bool                  `form:"dry_run"`
+	Async  bool                  `form:"async"`
+}
+
+type ImportUserRowResult struct {
+	Row     int               `json:"row"`
+	Email   string            `json:"email,omitempty"`
+	Success bool              `json:"success"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+type ImportUserReport struct {
+	DryRun    bool                  `json:"dry_run"`
+	Total     int                   `json:"total"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Rows      []ImportUserRowResult `json:"rows"`
+}
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatXLSX   = "xlsx"
+)
+
+// UserExportColumns lists the columns a caller can pick for an export.
+var UserExportColumns = []string{"id", "name", "email", "role", "version", "created_at", "updated_at", "deleted_at"}
+
+var defaultUserExportColumns = []string{"id", "name", "email", "role", "created_at", "updated_at"}
+
+type ExportUserRequest struct {
+	Format  string `form:"format" binding:"omitempty,oneof=csv ndjson xlsx"`
+	Columns string `form:"columns" binding:"omitempty,max=200" example:"id,name,email"`
+	Search  string `form:"search" binding:"omitempty,max=50"`
+	Deleted string `form:"deleted" binding:"omitempty,oneof=include only"`
+	Sort    string `form:"sort" binding:"omitempty,max=100" example:"-created_at,name"`
+	Async   bool   `form:"async"`
+
+	Query         *query.Query `form:"-" swaggerignore:"true"`
+	SelectColumns []string     `form:"-" swaggerignore:"true"`
+}
+
+// ParseQuery parses the filters and sort like the users list and resolves
+// the requested columns.
+func (e *ExportUserRequest) ParseQuery(values url.Values) error {
+	errs := query.Errors{}
+
+	parsed, err := query.Parse(UserQuerySchema, values)
+	if queryErrors, ok := err.(query.Errors); ok {
+		errs = queryErrors
+	} else if err != nil {
+		return err
+	}
+
+	e.SelectColumns = defaultUserExportColumns
+	if e.Columns != "" {
+		e.SelectColumns = nil
+		for _, column := range strings.Split(e.Columns, ",") {
+			column = strings.TrimSpace(column)
+			if !slices.Contains(UserExportColumns, column) {
+				errs["columns"] = column + " is not an exportable column, use one of " + strings.Join(UserExportColumns, ",")
+				break
+			}
+			e.SelectColumns = append(e.SelectColumns, column)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	e.Query = parsed
+	return nil
+}
+
+// ToQueryUserRequest returns the list filters of the export.
+func (e *ExportUserRequest) ToQueryUserRequest() *QueryUserRequest {
+	return &QueryUserRequest{
+		Search:  e.Search,
+		Deleted: e.Deleted,
+		Sort:    e.Sort,
+		Query:   e.Query,
+	}
+}
+
+type DeleteUserRequest struct {
+	Hard bool `form:"hard"`
 }
 
 type UserIDParam struct {
 	ID string `uri:"id" binding:"required,uuid"`
 }
 
+func (q *QueryUserRequest) ParseQuery(values url.Values) error {
+	parsed, err := query.Parse(UserQuerySchema, values)
+	if err != nil {
+		return err
+	}
+
+	q.Query = parsed
+	return nil
+}
+
 func (q *QueryUserRequest) SetDefaultPagination() {
 	if q.Page < 1 {
 		q.Page = 1
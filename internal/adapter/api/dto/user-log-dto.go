@@ -1,8 +1,36 @@
 package dto
 
+import (
+	"net/url"
+
+	"codetest/internal/query"
+)
+
 type QueryUserLogRequest struct {
-	Page     int `form:"page" binding:"omitempty,min=1"`
-	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
+	Sort     string `form:"sort" binding:"omitempty,max=100" example:"-created_at"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+
+	Query *query.Query `form:"-" swaggerignore:"true"`
+}
+
+// UserLogQuerySchema whitelists the fields accepted by filter[...] and sort
+// on the user logs list.
+var UserLogQuerySchema = query.Schema{
+	"user_id":    {Column: "user_id", Type: query.TypeString, Operators: query.EnumOperators, Sortable: true},
+	"event":      {Column: "event", Type: query.TypeString, Operators: query.EnumOperators, Sortable: true},
+	"request_id": {Column: "request_id", Type: query.TypeString, Operators: query.EnumOperators},
+	"created_at": {Column: "created_at", Type: query.TypeTime, Operators: query.TimeOperators, Sortable: true},
+}
+
+func (q *QueryUserLogRequest) ParseQuery(values url.Values) error {
+	parsed, err := query.Parse(UserLogQuerySchema, values)
+	if err != nil {
+		return err
+	}
+
+	q.Query = parsed
+	return nil
 }
 
 func (q *QueryUserLogRequest) SetDefaultPagination() {
@@ -0,0 +1,42 @@
+package dto
+
+// ValidationMessage renders the client facing message for a failed
+// validation tag on field.
+func ValidationMessage(field, tag, param string) string {
+	switch tag {
+	case "required":
+		return field + " is required"
+	case "email":
+		return field + " must be a valid email address"
+	case "min":
+		return field + " must be at least " + param + " characters long"
+	case "max":
+		return field + " must be at most " + param + " characters long"
+	case "gte":
+		return field + " must be greater than or equal to " + param
+	case "lte":
+		return field + " must be less than or equal to " + param
+	case "eq":
+		return field + " must be equal to " + param
+	case "ne":
+		return field + " must not be equal to " + param
+	case "gt":
+		return field + " must be greater than " + param
+	case "lt":
+		return field + " must be less than " + param
+	case "len":
+		return field + " must be exactly " + param + " characters long"
+	case "oneof":
+		return field + " must be one of the following values: " + param
+	case "notoneof":
+		return field + " must not be one of the following values: " + param
+	case "url":
+		return field + " must be a valid URL"
+	case "uuid":
+		return field + " must be a valid UUID"
+	case "json":
+		return field + " must be a valid JSON"
+	default:
+		return field + " is invalid"
+	}
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"log/slog"
+
 	"codetest/internal/adapter/api/dto"
 	"codetest/internal/adapter/api/middleware"
 	"codetest/internal/adapter/api/presenter"
 	"codetest/internal/adapter/api/util"
+	"codetest/internal/logger"
 	portservice "codetest/internal/port/service"
 
 	"github.com/gin-gonic/gin"
@@ -49,6 +52,7 @@ func (h *AuthHandler) registerRoutes() {
 // @Success 200 {object} presenter.JsonResponseWithoutPagination{data=dto.LoginResponse}
 // @Failure 400 {object} presenter.JsonResponseWithoutPagination
 // @Failure 401 {object} presenter.JsonResponseWithoutPagination
+// @Failure 403 {object} presenter.JsonResponseWithoutPagination
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	val, ok := c.Get("validatedRequest")
@@ -86,6 +90,19 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
+	if user.DisabledAt != nil {
+		c.JSON(403, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Error:   "Account is disabled",
+		})
+		return
+	}
+
+	// The login goes on with the old hash, it is upgraded on the next one.
+	if err := h.userService.UpgradePasswordHash(c, user, request.Password); err != nil {
+		slog.WarnContext(c, "Failed to upgrade password hash", slog.String("user_id", user.ID.String()), logger.Error(err))
+	}
+
 	accessToken, err := h.jwtService.GenerateAccessToken(user)
 	if err != nil {
 		c.JSON(400, presenter.JsonResponseWithoutPagination{
@@ -124,9 +141,16 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Router /auth/me [get]
 func (h *AuthHandler) Me(c *gin.Context) {
-	userId, _ := c.Get("userId")
+	userId, err := uuid.Parse(c.GetString("userId"))
+	if err != nil {
+		c.JSON(401, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
 
-	user, err := h.userService.GetOneByID(c, uuid.MustParse(userId.(string)))
+	user, err := h.userService.GetOneByID(c, userId)
 	if err != nil {
 		c.JSON(401, presenter.JsonResponseWithoutPagination{
 			Success: false,
@@ -161,6 +185,35 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
+	claimedId, err := h.jwtService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		c.JSON(401, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userId, err := uuid.Parse(claimedId)
+	if err != nil {
+		c.JSON(401, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
+	// Disabled users keep their current tokens until expiry but cannot
+	// extend the session.
+	user, err := h.userService.GetOneByID(c, userId)
+	if err != nil || user.DisabledAt != nil {
+		c.JSON(401, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Error:   "Unauthorized",
+		})
+		return
+	}
+
 	accessToken, refreshToken, err := h.jwtService.RefreshToken(refreshToken)
 	if err != nil {
 		c.JSON(401, presenter.JsonResponseWithoutPagination{
@@ -1,18 +1,24 @@
 package handler
 
 import (
+	"net/http"
+
+	"codetest/internal/adapter/api/dto"
 	"codetest/internal/adapter/api/presenter"
+	portservice "codetest/internal/port/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 type HealthCheckHandler struct {
-	router *gin.RouterGroup
+	router        *gin.RouterGroup
+	healthService portservice.HealthService
 }
 
-func NewHealthCheckHandler(router *gin.RouterGroup) *HealthCheckHandler {
+func NewHealthCheckHandler(router *gin.RouterGroup, healthService portservice.HealthService) *HealthCheckHandler {
 	handler := &HealthCheckHandler{
-		router: router,
+		router:        router,
+		healthService: healthService,
 	}
 
 	handler.registerRoutes()
@@ -24,17 +30,20 @@ func (h *HealthCheckHandler) registerRoutes() {
 	route := h.router.Group("/health")
 	{
 		route.GET("", h.HealthCheck)
+		route.GET("/live", h.HealthCheck)
+		route.GET("/ready", h.Ready)
 	}
 }
 
 // HealtCheck godoc
-// @Summary Health Check
-// @Description Check the health of the service
+// @Summary Liveness Check
+// @Description Report that the process is running. Dependencies are not checked, see /health/ready.
 // @Tags Health
 // @Accept json
 // @Produce json
 // @Success 200 {object} presenter.JsonResponse
 // @Router /health [get]
+// @Router /health/live [get]
 func (h *HealthCheckHandler) HealthCheck(c *gin.Context) {
 	c.JSON(200, presenter.JsonResponse{
 		Success: true,
@@ -44,3 +53,32 @@ func (h *HealthCheckHandler) HealthCheck(c *gin.Context) {
 		},
 	})
 }
+
+// Ready godoc
+// @Summary Readiness Check
+// @Description Check Postgres, MongoDB, Redis and the user log subscriber. Results are cached for a few seconds.
+// @Description Fails with 503 when a dependency is down or the server is shutting down.
+// @Tags Health
+// @Accept json
+// @Produce json
+// @Success 200 {object} presenter.JsonResponseWithoutPagination{data=dto.HealthReport}
+// @Failure 503 {object} presenter.JsonResponseWithoutPagination{data=dto.HealthReport}
+// @Router /health/ready [get]
+func (h *HealthCheckHandler) Ready(c *gin.Context) {
+	report := h.healthService.Ready(c)
+
+	if report.Status != dto.HealthStatusUp {
+		c.JSON(http.StatusServiceUnavailable, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    report,
+			Error:   "Service is not ready",
+		})
+		return
+	}
+
+	c.JSON(200, presenter.JsonResponseWithoutPagination{
+		Success: true,
+		Data:    report,
+		Message: "Service is ready",
+	})
+}
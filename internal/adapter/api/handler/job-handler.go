@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/adapter/api/middleware"
+	"codetest/internal/adapter/api/presenter"
+	"codetest/internal/adapter/service"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	portservice "codetest/internal/port/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type JobHandler struct {
+	router      *gin.RouterGroup
+	jobService  portservice.JobService
+	userService portservice.UserService
+	jwtService  portservice.JWTService
+}
+
+func NewJobHandler(router *gin.RouterGroup, jobService portservice.JobService, userService portservice.UserService, jwtService portservice.JWTService) *JobHandler {
+	handler := &JobHandler{
+		router:      router,
+		jobService:  jobService,
+		userService: userService,
+		jwtService:  jwtService,
+	}
+
+	handler.registerRoutes()
+
+	return handler
+}
+
+func (h *JobHandler) registerRoutes() {
+	route := h.router.Group("/jobs", middleware.AccessTokenMiddleware(h.jwtService))
+	{
+		route.GET("/:id", middleware.ValidationMiddleware(dto.JobIDParam{}, middleware.BindUri), h.GetOneByID)
+		route.POST("/:id/cancel", middleware.ValidationMiddleware(dto.JobIDParam{}, middleware.BindUri), h.Cancel)
+		route.GET("/:id/artifact", middleware.ValidationMiddleware(dto.JobIDParam{}, middleware.BindUri), h.Artifact)
+	}
+}
+
+// GetOneByID godoc
+// @Summary Get Job
+// @Description Get the status, progress and result of a background job. Only its creator and admins can see it.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} presenter.JsonResponseWithoutPagination{data=model.JobModel}
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /jobs/{id} [get]
+func (h *JobHandler) GetOneByID(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(200, presenter.JsonResponseWithoutPagination{
+		Success: true,
+		Data:    job,
+		Message: "Job retrieved successfully",
+	})
+}
+
+// Cancel godoc
+// @Summary Cancel Job
+// @Description Cancel a queued job, or ask a running one to stop. Running jobs become cancelled once the worker notices.
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} presenter.JsonResponseWithoutPagination{data=model.JobModel}
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
+// @Failure 409 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /jobs/{id}/cancel [post]
+func (h *JobHandler) Cancel(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobService.Cancel(c, job.ID)
+	switch {
+	case errors.Is(err, service.ErrJobFinished):
+		c.JSON(http.StatusConflict, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    job,
+			Error:   "Job already finished",
+		})
+		return
+	case err != nil:
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(200, presenter.JsonResponseWithoutPagination{
+		Success: true,
+		Data:    job,
+		Message: "Job cancellation requested",
+	})
+}
+
+// Artifact godoc
+// @Summary Download Job Artifact
+// @Description Download the file produced by a succeeded job, such as an asynchronous users export
+// @Tags Jobs
+// @Produce octet-stream
+// @Param id path string true "Job ID"
+// @Success 200 {file} file
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
+// @Failure 409 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /jobs/{id}/artifact [get]
+func (h *JobHandler) Artifact(c *gin.Context) {
+	job, ok := h.getJob(c)
+	if !ok {
+		return
+	}
+
+	if !job.HasArtifact {
+		c.JSON(http.StatusConflict, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Job has no artifact",
+		})
+		return
+	}
+
+	c.FileAttachment(job.ArtifactPath, job.ID.String()+filepath.Ext(job.ArtifactPath))
+}
+
+// getJob loads the job of the :id param and checks it belongs to the caller
+// or the caller is an admin. Jobs of other users are reported as not found.
+func (h *JobHandler) getJob(c *gin.Context) (*model.JobModel, bool) {
+	val, ok := c.Get("validatedRequest")
+	if !ok {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request data",
+		})
+		return nil, false
+	}
+
+	jobId := uuid.MustParse(val.(*dto.JobIDParam).ID)
+
+	job, err := h.jobService.GetOneByID(c, jobId)
+	if errors.Is(err, portrepository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Job not found",
+		})
+		return nil, false
+	}
+
+	if err != nil {
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return nil, false
+	}
+
+	authID := c.GetString("userId")
+	if job.CreatedBy != authID {
+		userId, _ := uuid.Parse(authID)
+		user, err := h.userService.GetOneByID(c, userId)
+		if err != nil || user.Role != model.UserRoleAdmin {
+			c.JSON(http.StatusNotFound, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Data:    nil,
+				Error:   "Job not found",
+			})
+			return nil, false
+		}
+	}
+
+	return job, true
+}
+
+// respondJobAccepted answers a request whose work was handed to a job.
+func respondJobAccepted(c *gin.Context, job *model.JobModel, message string) {
+	c.Header("Location", fmt.Sprintf("/api/jobs/%s", job.ID))
+	c.JSON(http.StatusAccepted, presenter.JsonResponseWithoutPagination{
+		Success: true,
+		Data:    job,
+		Message: message,
+	})
+}
@@ -1,37 +1,57 @@
 package handler
 
 import (
+	"bytes"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"codetest/internal/adapter/api/dto"
 	"codetest/internal/adapter/api/middleware"
 	"codetest/internal/adapter/api/presenter"
+	"codetest/internal/adapter/api/util"
+	"codetest/internal/logger"
 	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
 	portservice "codetest/internal/port/service"
+	"codetest/internal/requestctx"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/google/uuid"
-	"github.com/redis/go-redis/v9"
+)
+
+const (
+	maxPatchBodySize  = 1 << 20
+	maxImportFileSize = 10 << 20
 )
 
 type UserHandler struct {
-	router         *gin.RouterGroup
-	userService    portservice.UserService
-	jwtService     portservice.JWTService
-	redisClient    *redis.Client
-	userLogChannel string
+	router      *gin.RouterGroup
+	userService portservice.UserService
+	jobService  portservice.JobService
+	jwtService  portservice.JWTService
+	events      portservice.EventPublisher
+	idempotency gin.HandlerFunc
 }
 
-func NewUserHandler(router *gin.RouterGroup, userService portservice.UserService, jwtService portservice.JWTService, redisClient *redis.Client, userLogChachan string) *UserHandler {
+// NewUserHandler registers the user routes. Changes are audited by the user
+// service, reads by the handler through events. idempotency guards the
+// routes creating resources.
+func NewUserHandler(router *gin.RouterGroup, userService portservice.UserService, jobService portservice.JobService, jwtService portservice.JWTService, events portservice.EventPublisher, idempotency gin.HandlerFunc) *UserHandler {
 	handler := &UserHandler{
-		router:         router,
-		userService:    userService,
-		jwtService:     jwtService,
-		redisClient:    redisClient,
-		userLogChannel: userLogChachan,
+		router:      router,
+		userService: userService,
+		jobService:  jobService,
+		jwtService:  jwtService,
+		events:      events,
+		idempotency: idempotency,
 	}
 
 	handler.registerRoutes()
@@ -42,17 +62,40 @@ func NewUserHandler(router *gin.RouterGroup, userService portservice.UserService
 func (h *UserHandler) registerRoutes() {
 	route := h.router.Group("/users", middleware.AccessTokenMiddleware(h.jwtService))
 	{
-		route.GET("", middleware.ValidationMiddleware(dto.QueryUserRequest{}, middleware.BindQuery), h.Find)
-		route.GET("/:id", middleware.ValidationMiddleware(dto.UserIDParam{}, middleware.BindUri), h.GetOneByID)
-		route.POST("", middleware.ValidationMiddleware(dto.CreateUserRequest{}, middleware.BindJSON), h.Create)
+		route.GET("", middleware.AuditMiddleware(h.events, model.UserLogEventRead, auditURL), middleware.ValidationMiddleware(dto.QueryUserRequest{}, middleware.BindQuery), h.Find)
+		route.GET("/export", middleware.ValidationMiddleware(dto.ExportUserRequest{}, middleware.BindQuery), h.Export)
+		route.GET("/:id", middleware.AuditMiddleware(h.events, model.UserLogEventRead, auditUser), middleware.ValidationMiddleware(dto.UserIDParam{}, middleware.BindUri), h.GetOneByID)
+		route.POST("", h.idempotency, middleware.ValidationMiddleware(dto.CreateUserRequest{}, middleware.BindJSON), h.Create)
+		route.POST("/import", h.idempotency, middleware.ValidationMiddleware(dto.ImportUserRequest{}, middleware.BindMultipartForm), h.Import)
+		route.POST("/purge", h.idempotency, middleware.RoleMiddleware(h.userService, model.UserRoleAdmin), middleware.ValidationMiddleware(dto.PurgeUsersRequest{}, middleware.BindJSON), h.PurgeDeleted)
+		route.POST("/rehash", h.idempotency, middleware.RoleMiddleware(h.userService, model.UserRoleAdmin), h.CheckPasswordHashes)
 		route.PUT("/:id", middleware.ValidationMiddleware(dto.UserIDParam{}, middleware.BindUri), middleware.ValidationMiddleware(dto.UpdateUserRequest{}, middleware.BindJSON), h.Update)
-		route.DELETE("/:id", middleware.ValidationMiddleware(dto.UserIDParam{}, middleware.BindUri), h.Delete)
+		route.PATCH("/:id", middleware.ValidationMiddleware(dto.UserIDParam{}, middleware.BindUri), h.Patch)
+		route.DELETE("/:id", middleware.ValidationMiddleware(dto.UserIDParam{}, middleware.BindUri), middleware.ValidationMiddleware(dto.DeleteUserRequest{}, middleware.BindQuery), h.Delete)
+		route.POST("/:id/restore", h.idempotency, middleware.ValidationMiddleware(dto.UserIDParam{}, middleware.BindUri), h.Restore)
+	}
+}
+
+func auditURL(c *gin.Context) map[string]interface{} {
+	return map[string]interface{}{"full_url": c.Request.URL.String()}
+}
+
+// auditUser names the user GetOneByID left in the context.
+func auditUser(c *gin.Context) map[string]interface{} {
+	val, _ := c.Get("user")
+	user, ok := val.(*model.UserModel)
+	if !ok {
+		return map[string]interface{}{"id": c.Param("id")}
 	}
+	return map[string]interface{}{"id": user.ID, "email": user.Email, "name": user.Name}
 }
 
 // Get Users godoc
 // @Summary Get Users
-// @Description Get a list of users
+// @Description Get a list of users. Supports filter[field][operator]=value on id, name, email, created_at and updated_at
+// @Description (operators: eq, ne, like, ilike, in, gt, gte, lt, lte) and sort=-created_at,name.
+// @Description search matches partial or misspelled names and emails, ordered by relevance unless sort is given.
+// @Description deleted=include also lists soft deleted users, deleted=only lists the trash.
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -93,20 +136,6 @@ func (h *UserHandler) Find(c *gin.Context) {
 		TotalPages: totalPages,
 	}
 
-	authID, _ := c.Get("userId")
-	bytes, _ := json.Marshal(model.UserLogModel{
-		UserID: authID.(string),
-		Event:  model.UserLogEventRead,
-		Data: map[string]string{
-			"full_url": c.Request.URL.String(),
-		},
-		CreatedAt: time.Now(),
-	})
-
-	if err := h.redisClient.Publish(c, h.userLogChannel, bytes).Err(); err != nil {
-		log.Printf("Failed to publish user reading event to Redis: %v", err.Error())
-	}
-
 	c.JSON(200, presenter.JsonResponse{
 		Success:    true,
 		Data:       users,
@@ -123,6 +152,9 @@ func (h *UserHandler) Find(c *gin.Context) {
 // @Produce json
 // @Param id path string true "User ID"
 // @Success 200 {object} presenter.JsonResponseWithoutPagination{data=model.UserModel}
+// @Header 200 {string} ETag "Current version of the user, send it back as If-Match when updating"
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
 // @Security ApiKeyAuth
 // @Router /users/{id} [get]
 func (h *UserHandler) GetOneByID(c *gin.Context) {
@@ -139,6 +171,15 @@ func (h *UserHandler) GetOneByID(c *gin.Context) {
 	request := val.(*dto.UserIDParam)
 
 	user, err := h.userService.GetOneByID(c, uuid.MustParse(request.ID))
+	if errors.Is(err, portrepository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "User not found",
+		})
+		return
+	}
+
 	if err != nil {
 		c.JSON(500, presenter.JsonResponseWithoutPagination{
 			Success: false,
@@ -148,22 +189,8 @@ func (h *UserHandler) GetOneByID(c *gin.Context) {
 		return
 	}
 
-	authID, _ := c.Get("userId")
-	bytes, _ := json.Marshal(model.UserLogModel{
-		UserID: authID.(string),
-		Event:  model.UserLogEventRead,
-		Data: map[string]interface{}{
-			"email": user.Email,
-			"name":  user.Name,
-			"id":    user.ID,
-		},
-		CreatedAt: time.Now(),
-	})
-
-	if err := h.redisClient.Publish(c, h.userLogChannel, bytes).Err(); err != nil {
-		log.Printf("Failed to publish user reading event to Redis: %v", err.Error())
-	}
-
+	c.Set("user", user)
+	c.Header("ETag", util.FormatETag(user.Version))
 	c.JSON(200, presenter.JsonResponseWithoutPagination{
 		Success: true,
 		Data:    user,
@@ -178,6 +205,7 @@ func (h *UserHandler) GetOneByID(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param user body dto.CreateUserRequest true "User data"
+// @Param Idempotency-Key header string false "Makes the request safe to retry, the first response is replayed for the same key"
 // @Success 200 {object} presenter.JsonResponseWithoutPagination{data=model.UserModel}
 // @Failure 400 {object} presenter.JsonResponseWithoutPagination
 // @Failure 422 {object} presenter.JsonResponseWithoutPagination
@@ -207,39 +235,302 @@ func (h *UserHandler) Create(c *gin.Context) {
 		return
 	}
 
-	authID, _ := c.Get("userId")
-	bytes, _ := json.Marshal(model.UserLogModel{
-		UserID: authID.(string),
-		Event:  model.UserLogEventCreate,
-		Data: map[string]interface{}{
-			"email": request.Email,
-			"name":  request.Name,
-		},
-		CreatedAt: time.Now(),
+	c.JSON(200, presenter.JsonResponseWithoutPagination{
+		Success: true,
+		Data:    nil,
+		Message: "User created successfully",
 	})
+}
+
+// Import Users godoc
+// @Summary Import Users
+// @Description Create users in bulk from a CSV (name,email,password[,confirm_password] header) or NDJSON file.
+// @Description Every row is validated with the Create User rules and reported individually; dry_run only validates.
+// @Description With async=true the import runs as a background job and the report becomes the job result.
+// @Tags Users
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV or NDJSON file"
+// @Param format formData string false "csv or ndjson, detected from the file extension when omitted"
+// @Param dry_run formData bool false "Validate without creating users"
+// @Param async formData bool false "Run the import as a background job"
+// @Param Idempotency-Key header string false "Makes the request safe to retry, the first response is replayed for the same key"
+// @Success 200 {object} presenter.JsonResponseWithoutPagination{data=dto.ImportUserReport}
+// @Success 202 {object} presenter.JsonResponseWithoutPagination{data=model.JobModel}
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 413 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /users/import [post]
+func (h *UserHandler) Import(c *gin.Context) {
+	val, ok := c.Get("validatedRequest")
+	if !ok {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request data",
+		})
+		return
+	}
 
-	if err := h.redisClient.Publish(c, h.userLogChannel, bytes).Err(); err != nil {
-		log.Printf("Failed to publish user creating event to Redis: %v", err.Error())
+	request := val.(*dto.ImportUserRequest)
+
+	if request.File.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Import file must be at most 10MB",
+		})
+		return
+	}
+
+	format := request.Format
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(request.File.Filename)) {
+		case ".csv":
+			format = dto.ImportFormatCSV
+		case ".ndjson", ".jsonl":
+			format = dto.ImportFormatNDJSON
+		default:
+			c.JSON(400, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Data:    nil,
+				Error:   map[string]string{"format": "format is required when the file extension is not .csv, .ndjson or .jsonl"},
+			})
+			return
+		}
+	}
+
+	file, err := request.File.Open()
+	if err != nil {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Failed to read the uploaded file",
+		})
+		return
+	}
+	defer file.Close()
+
+	authID, _ := c.Get("userId")
+
+	if request.Async {
+		job, err := h.jobService.Enqueue(c, model.JobTypeUserImport, dto.UserImportJobPayload{
+			FileName: request.File.Filename,
+			Format:   format,
+			DryRun:   request.DryRun,
+		}, file, authID.(string))
+		if err != nil {
+			c.JSON(500, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Data:    nil,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		respondJobAccepted(c, job, "Import queued")
+		return
+	}
+
+	report, err := h.userService.Import(c, file, request.File.Filename, format, request.DryRun)
+	if err != nil {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	message := fmt.Sprintf("%d of %d users imported", report.Succeeded, report.Total)
+	if report.DryRun {
+		message = fmt.Sprintf("%d of %d rows are valid", report.Succeeded, report.Total)
 	}
 
 	c.JSON(200, presenter.JsonResponseWithoutPagination{
 		Success: true,
-		Data:    nil,
-		Message: "User created successfully",
+		Data:    report,
+		Message: message,
 	})
 }
 
+// Export Users godoc
+// @Summary Export Users
+// @Description Stream every user matching the users list filters as CSV (default), NDJSON or XLSX.
+// @Description columns picks the exported columns among id, name, email, role, version, created_at, updated_at and deleted_at.
+// @Description With async=true the file is produced by a background job and downloaded from GET /jobs/{id}/artifact.
+// @Tags Users
+// @Produce text/csv,application/x-ndjson,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet,json
+// @Param page query dto.ExportUserRequest false "Query params"
+// @Success 200 {file} file
+// @Success 202 {object} presenter.JsonResponseWithoutPagination{data=model.JobModel}
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /users/export [get]
+func (h *UserHandler) Export(c *gin.Context) {
+	val, ok := c.Get("validatedRequest")
+	if !ok {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request data",
+		})
+		return
+	}
+
+	request := val.(*dto.ExportUserRequest)
+	if request.Format == "" {
+		request.Format = dto.ExportFormatCSV
+	}
+
+	authID, _ := c.Get("userId")
+
+	if request.Async {
+		job, err := h.jobService.Enqueue(c, model.JobTypeUserExport, dto.UserExportJobPayload{
+			RawQuery: c.Request.URL.RawQuery,
+		}, nil, authID.(string))
+		if err != nil {
+			c.JSON(500, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Data:    nil,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		respondJobAccepted(c, job, "Export queued")
+		return
+	}
+
+	contentTypes := map[string]string{
+		dto.ExportFormatCSV:    "text/csv; charset=utf-8",
+		dto.ExportFormatNDJSON: "application/x-ndjson",
+		dto.ExportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	}
+
+	c.Header("Content-Type", contentTypes[request.Format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().UTC().Format("20060102-150405"), request.Format))
+	c.Status(200)
+
+	count, err := h.userService.Export(c, request, &flushWriter{writer: c.Writer})
+	if err != nil {
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			c.Writer.Header().Del("Content-Disposition")
+			c.JSON(500, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Data:    nil,
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		slog.ErrorContext(c, "Failed to export users", slog.Int("rows", count), logger.Error(err))
+		return
+	}
+}
+
+// Purge Deleted Users godoc
+// @Summary Purge Deleted Users
+// @Description Queue a background job permanently removing users soft deleted more than older_than_days ago (admin only)
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param request body dto.PurgeUsersRequest true "Retention period"
+// @Param Idempotency-Key header string false "Makes the request safe to retry, the first response is replayed for the same key"
+// @Success 202 {object} presenter.JsonResponseWithoutPagination{data=model.JobModel}
+// @Header 202 {string} Location "URL of the job"
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 403 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /users/purge [post]
+func (h *UserHandler) PurgeDeleted(c *gin.Context) {
+	val, ok := c.Get("validatedRequest")
+	if !ok {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request data",
+		})
+		return
+	}
+
+	request := val.(*dto.PurgeUsersRequest)
+	authID, _ := c.Get("userId")
+
+	job, err := h.jobService.Enqueue(c, model.JobTypeUserPurge, request, nil, authID.(string))
+	if err != nil {
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	respondJobAccepted(c, job, "Purge queued")
+}
+
+// Check Password Hashes godoc
+// @Summary Check Password Hashes
+// @Description Queue a background job counting the password hashes made with a bcrypt cost below PASSWORD_HASH_COST, they are re-hashed on their owner's next login (admin only)
+// @Tags Users
+// @Produce json
+// @Param Idempotency-Key header string false "Makes the request safe to retry, the first response is replayed for the same key"
+// @Success 202 {object} presenter.JsonResponseWithoutPagination{data=model.JobModel}
+// @Header 202 {string} Location "URL of the job"
+// @Failure 403 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /users/rehash [post]
+func (h *UserHandler) CheckPasswordHashes(c *gin.Context) {
+	authID, _ := c.Get("userId")
+
+	job, err := h.jobService.Enqueue(c, model.JobTypeUserRehash, nil, nil, authID.(string))
+	if err != nil {
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	respondJobAccepted(c, job, "Password hash check queued")
+}
+
+// flushWriter pushes every chunk to the client as soon as it is written.
+type flushWriter struct {
+	writer gin.ResponseWriter
+}
+
+func (w *flushWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.writer.Flush()
+	return n, err
+}
+
 // Update User godoc
-// @Summary Update User
-// @Description Update user
+// @Summary Replace User
+// @Description Replace the user's name and email. The password is only changed when provided.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
+// @Param If-Match header string true "Strong ETag returned by GET /users/{id}, or * for any version"
 // @Param request body dto.UpdateUserRequest true "User data"
 // @Success 200 {object} presenter.JsonResponseWithoutPagination
+// @Header 200 {string} ETag "New version of the user"
 // @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
+// @Failure 412 {object} presenter.JsonResponseWithoutPagination
 // @Failure 422 {object} presenter.JsonResponseWithoutPagination
+// @Failure 428 {object} presenter.JsonResponseWithoutPagination
 // @Failure 500 {object} presenter.JsonResponseWithoutPagination
 // @Security ApiKeyAuth
 // @Router /users/{id} [put]
@@ -257,9 +548,86 @@ func (h *UserHandler) Update(c *gin.Context) {
 	request := val.(*dto.UpdateUserRequest)
 	userId := uuid.MustParse(c.Param("id"))
 
-	err := h.userService.Update(c, userId, request)
+	version, ok := h.ifMatchVersion(c, userId)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.Update(c, userId, version, request)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, presenter.JsonResponseWithoutPagination{
+		h.handleUpdateError(c, err)
+		return
+	}
+
+	c.Header("ETag", util.FormatETag(user.Version))
+	c.JSON(200, presenter.JsonResponseWithoutPagination{
+		Success: true,
+		Data:    nil,
+		Message: "User updated successfully",
+	})
+}
+
+// Patch User godoc
+// @Summary Patch User
+// @Description Partially update a user with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902).
+// @Description The patched user is validated with the same rules as PUT; send "password" to change it.
+// @Tags Users
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param If-Match header string true "Strong ETag returned by GET /users/{id}, or * for any version"
+// @Param request body object true "Merge patch document or JSON Patch operations"
+// @Success 200 {object} presenter.JsonResponseWithoutPagination{data=model.UserModel}
+// @Header 200 {string} ETag "New version of the user"
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
+// @Failure 412 {object} presenter.JsonResponseWithoutPagination
+// @Failure 415 {object} presenter.JsonResponseWithoutPagination
+// @Failure 422 {object} presenter.JsonResponseWithoutPagination
+// @Failure 428 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /users/{id} [patch]
+func (h *UserHandler) Patch(c *gin.Context) {
+	val, ok := c.Get("validatedRequest")
+	if !ok {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request data",
+		})
+		return
+	}
+
+	userId := uuid.MustParse(val.(*dto.UserIDParam).ID)
+
+	version, ok := h.ifMatchVersion(c, userId)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPatchBodySize))
+	if err != nil {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request body",
+		})
+		return
+	}
+
+	user, err := h.userService.GetOneByID(c, userId)
+	if errors.Is(err, portrepository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "User not found",
+		})
+		return
+	}
+
+	if err != nil {
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
 			Success: false,
 			Data:    nil,
 			Error:   err.Error(),
@@ -267,47 +635,213 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	authID, _ := c.Get("userId")
-	bytes, _ := json.Marshal(model.UserLogModel{
-		UserID: authID.(string),
-		Event:  model.UserLogEventUpdate,
-		Data: map[string]interface{}{
-			"email": request.Email,
-			"name":  request.Name,
-		},
-		CreatedAt: time.Now(),
-	})
+	if user.Version != version {
+		h.handleUpdateError(c, portrepository.ErrVersionConflict)
+		return
+	}
 
-	if err := h.redisClient.Publish(c, h.userLogChannel, bytes).Err(); err != nil {
-		log.Printf("Failed to publish user updating event to Redis: %v", err.Error())
+	current, err := json.Marshal(dto.UpdateUserRequest{Name: user.Name, Email: user.Email})
+	if err != nil {
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return
 	}
 
+	patched, err := util.ApplyPatch(current, patch, c.GetHeader("Content-Type"))
+	if err != nil {
+		status := 400
+		if errors.Is(err, util.ErrUnsupportedPatchType) {
+			status = http.StatusUnsupportedMediaType
+		}
+
+		c.JSON(status, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	request := &dto.UpdateUserRequest{}
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Patched user is invalid: " + err.Error(),
+		})
+		return
+	}
+
+	if err := binding.Validator.ValidateStruct(request); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   middleware.ValidationErrorMessages(request, err, middleware.BindJSON),
+		})
+		return
+	}
+
+	updated, err := h.userService.Update(c, userId, version, request)
+	if err != nil {
+		h.handleUpdateError(c, err)
+		return
+	}
+
+	user.Name, user.Email, user.Version = updated.Name, updated.Email, updated.Version
+
+	c.Header("ETag", util.FormatETag(user.Version))
 	c.JSON(200, presenter.JsonResponseWithoutPagination{
 		Success: true,
-		Data:    nil,
+		Data:    user,
 		Message: "User updated successfully",
 	})
 }
 
+// ifMatchVersion returns the version If-Match expects, resolving "*" to the
+// current one, and answers the request itself when the precondition cannot
+// be evaluated or fails.
+func (h *UserHandler) ifMatchVersion(c *gin.Context, userId uuid.UUID) (int, bool) {
+	version, err := util.GetVersionFromIfMatch(c)
+	switch {
+	case errors.Is(err, util.ErrMissingIfMatch):
+		c.JSON(http.StatusPreconditionRequired, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return 0, false
+	case errors.Is(err, util.ErrWeakIfMatch):
+		c.JSON(http.StatusPreconditionFailed, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return 0, false
+	case err != nil:
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+
+	if version != util.AnyVersion {
+		return version, true
+	}
+
+	// A replica may not have the latest version yet.
+	user, err := h.userService.GetOneByID(requestctx.WithPrimaryRead(c), userId)
+	if errors.Is(err, portrepository.ErrNotFound) {
+		c.JSON(http.StatusPreconditionFailed, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "User not found",
+		})
+		return 0, false
+	}
+
+	if err != nil {
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+
+	return user.Version, true
+}
+
+func (h *UserHandler) handleUpdateError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, portrepository.ErrNotFound):
+		c.JSON(http.StatusNotFound, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "User not found",
+		})
+	case errors.Is(err, portrepository.ErrVersionConflict):
+		c.JSON(http.StatusPreconditionFailed, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "User was modified by another request, fetch it again and retry",
+		})
+	case errors.Is(err, portrepository.ErrAlreadyExists):
+		c.JSON(http.StatusUnprocessableEntity, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Email already exists",
+		})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+	}
+}
+
 // Delete User godoc
 // @Summary Delete User
-// @Description Delete user
+// @Description Soft delete a user. Admins can pass hard=true to permanently purge the user, including one already in the trash.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
+// @Param hard query bool false "Permanently purge the user (admin only)"
 // @Success 200 {object} presenter.JsonResponseWithoutPagination
 // @Failure 400 {object} presenter.JsonResponseWithoutPagination
-// @Failure 422 {object} presenter.JsonResponseWithoutPagination
+// @Failure 403 {object} presenter.JsonResponseWithoutPagination
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
 // @Failure 500 {object} presenter.JsonResponseWithoutPagination
 // @Security ApiKeyAuth
 // @Router /users/{id} [delete]
 func (h *UserHandler) Delete(c *gin.Context) {
+	val, ok := c.Get("validatedRequest")
+	if !ok {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request data",
+		})
+		return
+	}
+
+	request := val.(*dto.DeleteUserRequest)
 	userId := uuid.MustParse(c.Param("id"))
 
-	err := h.userService.DeleteOneByID(c, userId)
+	message := "User deleted successfully"
+
+	var err error
+	if request.Hard {
+		middleware.RoleMiddleware(h.userService, model.UserRoleAdmin)(c)
+		if c.IsAborted() {
+			return
+		}
+
+		message = "User purged successfully"
+		err = h.userService.PurgeOneByID(c, userId)
+	} else {
+		err = h.userService.DeleteOneByID(c, userId)
+	}
+
+	if errors.Is(err, portrepository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "User not found",
+		})
+		return
+	}
+
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, presenter.JsonResponseWithoutPagination{
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
 			Success: false,
 			Data:    nil,
 			Error:   err.Error(),
@@ -315,21 +849,69 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	authID, _ := c.Get("userId")
-	bytes, _ := json.Marshal(model.UserLogModel{
-		UserID:    authID.(string),
-		Event:     model.UserLogEventDelete,
-		Data:      map[string]interface{}{"id": userId},
-		CreatedAt: time.Now(),
+	c.JSON(200, presenter.JsonResponseWithoutPagination{
+		Success: true,
+		Data:    nil,
+		Message: message,
 	})
+}
 
-	if err := h.redisClient.Publish(c, h.userLogChannel, bytes).Err(); err != nil {
-		log.Printf("Failed to publish user deleting event to Redis: %v", err.Error())
+// Restore User godoc
+// @Summary Restore User
+// @Description Restore a soft deleted user
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param Idempotency-Key header string false "Makes the request safe to retry, the first response is replayed for the same key"
+// @Success 200 {object} presenter.JsonResponseWithoutPagination
+// @Failure 400 {object} presenter.JsonResponseWithoutPagination
+// @Failure 404 {object} presenter.JsonResponseWithoutPagination
+// @Failure 409 {object} presenter.JsonResponseWithoutPagination
+// @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Security ApiKeyAuth
+// @Router /users/{id}/restore [post]
+func (h *UserHandler) Restore(c *gin.Context) {
+	val, ok := c.Get("validatedRequest")
+	if !ok {
+		c.JSON(400, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Invalid request data",
+		})
+		return
+	}
+
+	userId := uuid.MustParse(val.(*dto.UserIDParam).ID)
+
+	err := h.userService.RestoreOneByID(c, userId)
+	switch {
+	case errors.Is(err, portrepository.ErrNotFound):
+		c.JSON(http.StatusNotFound, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Deleted user not found",
+		})
+		return
+	case errors.Is(err, portrepository.ErrAlreadyExists):
+		c.JSON(http.StatusConflict, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "Email is already used by another user",
+		})
+		return
+	case err != nil:
+		c.JSON(500, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   err.Error(),
+		})
+		return
 	}
 
 	c.JSON(200, presenter.JsonResponseWithoutPagination{
 		Success: true,
 		Data:    nil,
-		Message: "User deleted successfully",
+		Message: "User restored successfully",
 	})
 }
@@ -1,9 +1,12 @@
 package handler
 
 import (
+	"errors"
+
 	"codetest/internal/adapter/api/dto"
 	"codetest/internal/adapter/api/middleware"
 	"codetest/internal/adapter/api/presenter"
+	portrepository "codetest/internal/port/repository"
 	portservice "codetest/internal/port/service"
 
 	"github.com/gin-gonic/gin"
@@ -36,7 +39,8 @@ func (h *UserLogHandler) registerRoutes() {
 
 // Find godoc
 // @Summary Get User Logs
-// @Description Get a list of user logs
+// @Description Get a list of user logs. Supports filter[field][operator]=value on user_id, event, request_id and created_at
+// @Description (operators: eq, ne, in, gt, gte, lt, lte) and sort=-created_at,event.
 // @Tags UserLogs
 // @Accept json
 // @Produce json
@@ -44,6 +48,7 @@ func (h *UserLogHandler) registerRoutes() {
 // @Success 200 {object} presenter.JsonResponse{data=[]model.UserLogModel}
 // @Failure 400 {object} presenter.JsonResponseWithoutPagination
 // @Failure 500 {object} presenter.JsonResponseWithoutPagination
+// @Failure 501 {object} presenter.JsonResponseWithoutPagination "No configured sink can read user logs"
 // @Security ApiKeyAuth
 // @Router /user-logs [get]
 func (h *UserLogHandler) Find(c *gin.Context) {
@@ -70,6 +75,14 @@ func (h *UserLogHandler) Find(c *gin.Context) {
 	request.SetDefaultPagination()
 
 	logs, total, err := h.userService.Find(c, request)
+	if errors.Is(err, portrepository.ErrNotSupported) {
+		c.JSON(501, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Data:    nil,
+			Error:   "User logs are not readable from the configured sinks",
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(500, presenter.JsonResponseWithoutPagination{
 			Success: false,
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"codetest/internal/adapter/publisher"
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditMiddleware publishes event once the handler succeeded, with the data
+// returned by data. It audits reads, changes are published by the services
+// making them. It must run after AccessTokenMiddleware.
+func AuditMiddleware(events portservice.EventPublisher, event model.UserLogEvent, data func(ctx *gin.Context) map[string]interface{}) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if ctx.IsAborted() || ctx.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		publisher.Emit(ctx.Request.Context(), events, event, data(ctx))
+	}
+}
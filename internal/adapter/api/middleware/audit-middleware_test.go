@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codetest/internal/adapter/publisher"
+	"codetest/internal/model"
+	"codetest/internal/requestctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var published []*model.UserLogModel
+	events := publisher.NewMemoryPublisher()
+	events.Subscribe(func(ctx context.Context, userLog *model.UserLogModel) error {
+		published = append(published, userLog)
+		return nil
+	})
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(requestctx.WithActor(c.Request.Context(), "user-1"))
+	})
+	router.GET("/:status", AuditMiddleware(events, model.UserLogEventRead, func(c *gin.Context) map[string]interface{} {
+		return map[string]interface{}{"status": c.Param("status")}
+	}), func(c *gin.Context) {
+		if c.Param("status") == "ok" {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusNotFound)
+	})
+
+	for _, path := range []string{"/ok", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("expected only the successful read to be audited, got %d events", len(published))
+	}
+	if published[0].UserID != "user-1" || published[0].Event != model.UserLogEventRead {
+		t.Errorf("unexpected event %+v", published[0])
+	}
+}
@@ -0,0 +1,386 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"codetest/internal/adapter/api/presenter"
+	"codetest/internal/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyLockTTL        = 60 * time.Second
+	idempotencyLockRetryDelay = 100 * time.Millisecond
+)
+
+// idempotencyLockRefreshInterval is how often the lock of a running request is
+// extended back to idempotencyLockTTL, so it only expires once its holder died.
+var idempotencyLockRefreshInterval = idempotencyLockTTL / 3
+
+// replayedHeaders are the response headers stored with an idempotent response
+// and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "Content-Disposition", "Location", "ETag"}
+
+// releaseLockScript deletes the lock only if it is still held by the caller,
+// so a request outliving the lock TTL cannot release somebody else's lock.
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// refreshLockScript extends the lock only if it is still held by the caller.
+var refreshLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+type idempotentResponse struct {
+	Fingerprint string              `json:"fingerprint"`
+	Status      int                 `json:"status"`
+	Header      map[string][]string `json:"header"`
+	Body        []byte              `json:"body"`
+}
+
+// IdempotencyMiddleware makes requests carrying an Idempotency-Key header safe
+// to retry. The first response for a key is stored for ttl and replayed for
+// later requests with the same key and payload, a different payload gets a
+// 422. Concurrent requests with the same key wait for the first one to
+// finish. Keys are scoped to the authenticated user, so it must run after
+// AccessTokenMiddleware on protected routes. Server errors are not stored so
+// they can be retried. Bodies larger than maxBodySize are rejected with a 413.
+func IdempotencyMiddleware(redisClient *redis.Client, ttl time.Duration, maxBodySize int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.JSON(400, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Error:   "Idempotency-Key must be at most 255 characters",
+			})
+			ctx.Abort()
+			return
+		}
+
+		bodyHash, cleanup, err := hashRequestBody(ctx, maxBodySize)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				ctx.JSON(http.StatusRequestEntityTooLarge, presenter.JsonResponseWithoutPagination{
+					Success: false,
+					Error:   fmt.Sprintf("Request body must be at most %d bytes", tooLarge.Limit),
+				})
+			} else {
+				ctx.JSON(400, presenter.JsonResponseWithoutPagination{
+					Success: false,
+					Error:   "Failed to read request body",
+				})
+			}
+			ctx.Abort()
+			return
+		}
+		defer cleanup()
+
+		scope := ctx.GetString("userId")
+		if scope == "" {
+			scope = "ip:" + ctx.ClientIP()
+		}
+
+		recordKey := "idempotency:" + hashParts(scope, ctx.Request.Method, ctx.FullPath(), key)
+		lockKey := recordKey + ":lock"
+		contentType := ctx.GetHeader("Content-Type")
+		if isMultipart(ctx) {
+			// The boundary is random, a retry rebuilding the form gets another one.
+			contentType = ctx.ContentType()
+		}
+		fingerprint := hashParts(ctx.Request.Method, ctx.Request.URL.Path, ctx.Request.URL.RawQuery, contentType, bodyHash)
+
+		token, err := acquireIdempotencyLock(ctx, redisClient, recordKey, lockKey, fingerprint)
+		if err != nil {
+			if !errors.Is(err, errIdempotencyReplayed) {
+				ctx.JSON(http.StatusServiceUnavailable, presenter.JsonResponseWithoutPagination{
+					Success: false,
+					Error:   err.Error(),
+				})
+			}
+			ctx.Abort()
+			return
+		}
+		defer func() {
+			if err := releaseLockScript.Run(ctx, redisClient, []string{lockKey}, token).Err(); err != nil {
+				slog.ErrorContext(ctx, "Failed to release idempotency lock", logger.Error(err))
+			}
+		}()
+
+		stopRefresh := refreshIdempotencyLock(context.WithoutCancel(ctx.Request.Context()), redisClient, lockKey, token)
+		defer stopRefresh()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		if recorder.Status() >= 500 {
+			return
+		}
+
+		response := idempotentResponse{
+			Fingerprint: fingerprint,
+			Status:      recorder.Status(),
+			Header:      make(map[string][]string),
+			Body:        recorder.body.Bytes(),
+		}
+		for _, name := range replayedHeaders {
+			if values := recorder.Header().Values(name); len(values) > 0 {
+				response.Header[name] = values
+			}
+		}
+
+		payload, _ := json.Marshal(response)
+		if err := redisClient.Set(ctx, recordKey, payload, ttl).Err(); err != nil {
+			slog.ErrorContext(ctx, "Failed to store idempotent response", logger.Error(err))
+		}
+	}
+}
+
+var (
+	errIdempotencyReplayed = errors.New("idempotent response replayed")
+	errIdempotencyTimeout  = errors.New("a request with the same Idempotency-Key is still in progress")
+)
+
+// acquireIdempotencyLock returns the lock token once the request may run. When
+// a response is already stored it is written to ctx and errIdempotencyReplayed
+// is returned instead.
+func acquireIdempotencyLock(ctx *gin.Context, redisClient *redis.Client, recordKey, lockKey, fingerprint string) (string, error) {
+	token := uuid.NewString()
+	deadline := time.Now().Add(idempotencyLockTTL)
+
+	for {
+		replayed, err := replayIdempotentResponse(ctx, redisClient, recordKey, fingerprint)
+		if err != nil {
+			return "", err
+		}
+		if replayed {
+			return "", errIdempotencyReplayed
+		}
+
+		acquired, err := redisClient.SetNX(ctx, lockKey, token, idempotencyLockTTL).Result()
+		if err != nil {
+			return "", err
+		}
+		if acquired {
+			return token, nil
+		}
+
+		if time.Now().After(deadline) {
+			return "", errIdempotencyTimeout
+		}
+
+		select {
+		case <-ctx.Request.Context().Done():
+			return "", ctx.Request.Context().Err()
+		case <-time.After(idempotencyLockRetryDelay):
+		}
+	}
+}
+
+// refreshIdempotencyLock keeps extending the lock until the returned function
+// is called, however long the handler takes.
+func refreshIdempotencyLock(ctx context.Context, redisClient *redis.Client, lockKey, token string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(idempotencyLockRefreshInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			if err := refreshLockScript.Run(ctx, redisClient, []string{lockKey}, token, idempotencyLockTTL.Milliseconds()).Err(); err != nil {
+				slog.WarnContext(ctx, "Failed to refresh idempotency lock", logger.Error(err))
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// hashRequestBody reads the body through a SHA-256 and puts back a copy for
+// the handler. Multipart uploads are spooled to a temporary file instead of
+// memory and hashed part by part, the returned cleanup removes the file.
+func hashRequestBody(ctx *gin.Context, maxBodySize int64) (string, func(), error) {
+	body := http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodySize)
+
+	if !isMultipart(ctx) {
+		hash := sha256.New()
+		data, err := io.ReadAll(io.TeeReader(body, hash))
+		if err != nil {
+			return "", nil, err
+		}
+
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(data))
+		return hex.EncodeToString(hash.Sum(nil)), func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "idempotency-*")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+	}
+
+	if _, err := io.Copy(file, body); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	bodyHash, err := hashMultipartBody(file, ctx.GetHeader("Content-Type"))
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	ctx.Request.Body = file
+	return bodyHash, cleanup, nil
+}
+
+// hashMultipartBody hashes the name, file name and content of every part of
+// a form, leaving out the boundary separating them.
+func hashMultipartBody(body io.Reader, contentType string) (string, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", err
+	}
+	if params["boundary"] == "" {
+		return "", http.ErrMissingBoundary
+	}
+
+	reader := multipart.NewReader(body, params["boundary"])
+	hash := sha256.New()
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		content := sha256.New()
+		if _, err := io.Copy(content, part); err != nil {
+			return "", err
+		}
+		hash.Write([]byte(hashParts(part.FormName(), part.FileName(), hex.EncodeToString(content.Sum(nil)))))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func isMultipart(ctx *gin.Context) bool {
+	return strings.HasPrefix(ctx.ContentType(), "multipart/")
+}
+
+func replayIdempotentResponse(ctx *gin.Context, redisClient *redis.Client, recordKey, fingerprint string) (bool, error) {
+	payload, err := redisClient.Get(ctx, recordKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var response idempotentResponse
+	if err := json.Unmarshal(payload, &response); err != nil {
+		return false, err
+	}
+
+	if response.Fingerprint != fingerprint {
+		ctx.JSON(http.StatusUnprocessableEntity, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Error:   "Idempotency-Key was already used with a different request",
+		})
+		return true, nil
+	}
+
+	for name, values := range response.Header {
+		for _, value := range values {
+			ctx.Writer.Header().Add(name, value)
+		}
+	}
+	ctx.Header(IdempotentReplayedHeader, "true")
+	ctx.Status(response.Status)
+	_, _ = ctx.Writer.Write(response.Body)
+
+	return true, nil
+}
+
+func hashParts(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body while writing it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
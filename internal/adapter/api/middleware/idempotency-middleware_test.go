@@ -0,0 +1,226 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+func newIdempotencyTestRouter(t *testing.T, calls *atomic.Int32, delay time.Duration) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	router := gin.New()
+	router.POST("/users", func(c *gin.Context) {
+		c.Set("userId", "user-1")
+	}, IdempotencyMiddleware(client, time.Minute, 1<<10), func(c *gin.Context) {
+		n := calls.Add(1)
+		time.Sleep(delay)
+		if n > 1 {
+			c.JSON(422, gin.H{"error": "Email already exists"})
+			return
+		}
+		c.Header("Location", "/api/users/1")
+		c.JSON(201, gin.H{"id": 1})
+	})
+
+	return router
+}
+
+func doIdempotentRequest(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("retry replays the stored response", func(t *testing.T) {
+		var calls atomic.Int32
+		router := newIdempotencyTestRouter(t, &calls, 0)
+
+		first := doIdempotentRequest(router, "key-1", `{"name":"john"}`)
+		second := doIdempotentRequest(router, "key-1", `{"name":"john"}`)
+
+		if calls.Load() != 1 {
+			t.Fatalf("expected the handler to run once, ran %d times", calls.Load())
+		}
+
+		if second.Code != 201 || second.Body.String() != first.Body.String() {
+			t.Errorf("expected replay of %d %s, got %d %s", first.Code, first.Body, second.Code, second.Body)
+		}
+
+		if second.Header().Get("Location") != "/api/users/1" || second.Header().Get(IdempotentReplayedHeader) != "true" {
+			t.Errorf("expected replayed headers, got %v", second.Header())
+		}
+	})
+
+	t.Run("key reused with another body is rejected", func(t *testing.T) {
+		var calls atomic.Int32
+		router := newIdempotencyTestRouter(t, &calls, 0)
+
+		doIdempotentRequest(router, "key-1", `{"name":"john"}`)
+		second := doIdempotentRequest(router, "key-1", `{"name":"jane"}`)
+
+		if second.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d", second.Code)
+		}
+	})
+
+	t.Run("requests without key are not deduplicated", func(t *testing.T) {
+		var calls atomic.Int32
+		router := newIdempotencyTestRouter(t, &calls, 0)
+
+		doIdempotentRequest(router, "", `{"name":"john"}`)
+		doIdempotentRequest(router, "", `{"name":"john"}`)
+
+		if calls.Load() != 2 {
+			t.Errorf("expected the handler to run twice, ran %d times", calls.Load())
+		}
+	})
+
+	t.Run("concurrent duplicates are serialized", func(t *testing.T) {
+		var calls atomic.Int32
+		router := newIdempotencyTestRouter(t, &calls, 200*time.Millisecond)
+
+		var wg sync.WaitGroup
+		codes := make([]int, 3)
+		for i := range codes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				codes[i] = doIdempotentRequest(router, "key-1", `{"name":"john"}`).Code
+			}()
+		}
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Fatalf("expected the handler to run once, ran %d times", calls.Load())
+		}
+
+		for _, code := range codes {
+			if code != 201 {
+				t.Errorf("expected every request to get 201, got %v", codes)
+				break
+			}
+		}
+	})
+	t.Run("oversized body is rejected", func(t *testing.T) {
+		var calls atomic.Int32
+		router := newIdempotencyTestRouter(t, &calls, 0)
+
+		w := doIdempotentRequest(router, "key-1", `{"name":"`+strings.Repeat("a", 2<<10)+`"}`)
+
+		if w.Code != http.StatusRequestEntityTooLarge || calls.Load() != 0 {
+			t.Errorf("expected 413 without running the handler, got %d after %d calls", w.Code, calls.Load())
+		}
+	})
+
+	t.Run("multipart upload reaches the handler and is replayed", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+
+		var calls atomic.Int32
+		router := gin.New()
+		router.POST("/import", IdempotencyMiddleware(client, time.Minute, 1<<10), func(c *gin.Context) {
+			calls.Add(1)
+			header, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			file, _ := header.Open()
+			defer file.Close()
+			content, _ := io.ReadAll(file)
+			c.String(200, string(content))
+		})
+
+		// Every attempt builds the form again, as a retrying client would, so
+		// each one gets its own random boundary.
+		upload := func(content string) *httptest.ResponseRecorder {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			_ = form.WriteField("format", "csv")
+			part, _ := form.CreateFormFile("file", "users.csv")
+			_, _ = part.Write([]byte(content))
+			_ = form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/import", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			req.Header.Set(IdempotencyKeyHeader, "key-1")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+
+		codes := make([]int, 2)
+		for i := range codes {
+			w := upload("name,email\n")
+			if w.Body.String() != "name,email\n" {
+				t.Fatalf("expected the uploaded file back, got %d %s", w.Code, w.Body)
+			}
+			codes[i] = w.Code
+		}
+
+		if calls.Load() != 1 || codes[1] != 200 {
+			t.Errorf("expected the upload to be handled once and replayed, got %d calls and %v", calls.Load(), codes)
+		}
+
+		if w := upload("name,email\nJohn,john@doe.com\n"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected another file under the same key to be rejected, got %d %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("lock is refreshed while the handler runs", func(t *testing.T) {
+		refreshInterval := idempotencyLockRefreshInterval
+		idempotencyLockRefreshInterval = 10 * time.Millisecond
+		t.Cleanup(func() { idempotencyLockRefreshInterval = refreshInterval })
+
+		gin.SetMode(gin.TestMode)
+		server := miniredis.RunT(t)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+
+		var lockTTL time.Duration
+		router := gin.New()
+		router.POST("/users", IdempotencyMiddleware(client, time.Minute, 1<<10), func(c *gin.Context) {
+			server.FastForward(idempotencyLockTTL - time.Second)
+			time.Sleep(100 * time.Millisecond)
+
+			for _, key := range server.Keys() {
+				if strings.HasSuffix(key, ":lock") {
+					lockTTL = server.TTL(key)
+				}
+			}
+			c.JSON(201, gin.H{"id": 1})
+		})
+
+		doIdempotentRequest(router, "key-1", `{"name":"john"}`)
+
+		if lockTTL != idempotencyLockTTL {
+			t.Errorf("expected the lock to be extended to %s, got %s", idempotencyLockTTL, lockTTL)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"codetest/internal/adapter/api/presenter"
 	"codetest/internal/adapter/api/util"
 	portservice "codetest/internal/port/service"
+	"codetest/internal/requestctx"
 
 	"github.com/gin-gonic/gin"
 )
@@ -31,6 +32,7 @@ func AccessTokenMiddleware(jwtService portservice.JWTService) gin.HandlerFunc {
 		}
 
 		ctx.Set("userId", userId)
+		ctx.Request = ctx.Request.WithContext(requestctx.WithActor(ctx.Request.Context(), userId))
 		ctx.Next()
 	}
 }
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"codetest/internal/adapter/api/presenter"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoggerMiddleware logs one line per request, replacing gin.Logger. Server
+// errors are logged at error level and client errors at warn level.
+func LoggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.String("route", ctx.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.Int("bytes", ctx.Writer.Size()),
+		}
+		if errors := ctx.Errors.ByType(gin.ErrorTypePrivate).String(); errors != "" {
+			attrs = append(attrs, slog.String("errors", errors))
+		}
+
+		slog.LogAttrs(ctx.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
+
+// RecoveryMiddleware turns a panic into a 500 response and logs it with the
+// stack trace, replacing gin.Recovery.
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					panic(r)
+				}
+
+				slog.ErrorContext(ctx.Request.Context(), "Panic recovered",
+					slog.Any("panic", r),
+					slog.String("stack", string(debug.Stack())),
+				)
+
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, presenter.JsonResponseWithoutPagination{
+					Success: false,
+					Error:   "Internal server error",
+				})
+			}
+		}()
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"time"
+
+	"codetest/internal/requestctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QueryTimeoutMiddleware bounds every database query made while handling the
+// request to timeout. Registered again on a route it overrides the global
+// value, zero lifting the bound.
+func QueryTimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(requestctx.WithQueryTimeout(ctx.Request.Context(), timeout))
+		ctx.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"codetest/internal/requestctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestQueryTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(QueryTimeoutMiddleware(30 * time.Second))
+	handler := func(c *gin.Context) {
+		c.String(http.StatusOK, requestctx.QueryTimeout(c.Request.Context()).String())
+	}
+	router.GET("/", handler)
+	router.GET("/export", QueryTimeoutMiddleware(5*time.Minute), handler)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/", want: "30s"},
+		{path: "/export", want: "5m0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Body.String() != tt.want {
+				t.Errorf("expected query timeout %s, got %s", tt.want, w.Body.String())
+			}
+		})
+	}
+}
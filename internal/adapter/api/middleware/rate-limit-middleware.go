@@ -0,0 +1,175 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"codetest/internal/adapter/api/presenter"
+	"codetest/internal/adapter/api/util"
+	"codetest/internal/logger"
+	portservice "codetest/internal/port/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript counts the requests of the last window in a sorted set
+// scored by their timestamp and records the current one when below limit. The
+// clock of the Redis server is used so every replica shares the same time.
+// It returns whether the request is allowed, the remaining requests and the
+// milliseconds until a slot frees up.
+var slidingWindowScript = redis.NewScript(`
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], 0, now - window)
+local count = redis.call("ZCARD", KEYS[1])
+
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, now .. ":" .. ARGV[3])
+	redis.call("PEXPIRE", KEYS[1], window)
+	count = count + 1
+	allowed = 1
+end
+
+local reset = window
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+
+return {allowed, limit - count, reset}`)
+
+type RateLimit struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimiter enforces per route group limits shared by every instance of the
+// API through Redis.
+type RateLimiter struct {
+	redisClient *redis.Client
+	jwtService  portservice.JWTService
+	limits      map[string]RateLimit
+	allowlist   []*net.IPNet
+}
+
+// NewRateLimiter builds a limiter for the given groups. allowlist holds IPs
+// or CIDRs of internal callers that are never limited.
+func NewRateLimiter(redisClient *redis.Client, jwtService portservice.JWTService, limits map[string]RateLimit, allowlist []string) (*RateLimiter, error) {
+	limiter := &RateLimiter{
+		redisClient: redisClient,
+		jwtService:  jwtService,
+		limits:      limits,
+	}
+
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit allowlist entry %q: %w", entry, err)
+		}
+		limiter.allowlist = append(limiter.allowlist, network)
+	}
+
+	return limiter, nil
+}
+
+// For returns the middleware limiting the given group. Authenticated requests
+// are counted per user, anonymous ones per client IP. Groups without a limit
+// are not limited. Redis failures let requests through rather than taking the
+// API down.
+func (l *RateLimiter) For(group string) gin.HandlerFunc {
+	limit, ok := l.limits[group]
+	if !ok || limit.Limit <= 0 || limit.Window <= 0 {
+		return func(ctx *gin.Context) {
+			ctx.Next()
+		}
+	}
+
+	policy := fmt.Sprintf("%d;w=%d", limit.Limit, int(limit.Window.Seconds()))
+
+	return func(ctx *gin.Context) {
+		if l.allowlisted(ctx.ClientIP()) {
+			ctx.Next()
+			return
+		}
+
+		key := fmt.Sprintf("ratelimit:%s:%s", group, l.subject(ctx))
+		result, err := slidingWindowScript.Run(ctx, l.redisClient, []string{key}, limit.Window.Milliseconds(), limit.Limit, uuid.NewString()).Int64Slice()
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to apply rate limit", logger.Error(err))
+			ctx.Next()
+			return
+		}
+
+		allowed, remaining, resetMs := result[0] == 1, result[1], result[2]
+		reset := strconv.Itoa(int(math.Ceil(float64(resetMs) / 1000)))
+
+		ctx.Header("RateLimit-Policy", policy)
+		ctx.Header("RateLimit-Limit", strconv.Itoa(limit.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+		ctx.Header("RateLimit-Reset", reset)
+
+		if !allowed {
+			ctx.Header("Retry-After", reset)
+			ctx.JSON(http.StatusTooManyRequests, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Error:   "Too many requests",
+			})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// subject identifies the caller, the access token is only validated here and
+// still checked by AccessTokenMiddleware on protected routes.
+func (l *RateLimiter) subject(ctx *gin.Context) string {
+	if token, err := util.GetJwtTokenFromHeader(ctx); err == nil {
+		if userId, err := l.jwtService.ValidateAccessToken(token); err == nil {
+			return "user:" + userId
+		}
+	}
+
+	return "ip:" + ctx.ClientIP()
+}
+
+func (l *RateLimiter) allowlisted(clientIP string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range l.allowlist {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	portservice "codetest/internal/port/service"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeJWTService accepts any token but "invalid" and uses it as the user ID.
+type fakeJWTService struct {
+	portservice.JWTService
+}
+
+func (fakeJWTService) ValidateAccessToken(token string) (string, error) {
+	if token == "invalid" {
+		return "", errors.New("invalid token")
+	}
+	return token, nil
+}
+
+func newRateLimitTestRouter(t *testing.T, allowlist []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	limiter, err := NewRateLimiter(client, fakeJWTService{}, map[string]RateLimit{
+		"users": {Limit: 2, Window: time.Minute},
+	}, allowlist)
+	if err != nil {
+		t.Fatalf("failed to create limiter: %v", err)
+	}
+
+	router := gin.New()
+	router.GET("/users", limiter.For("users"), func(c *gin.Context) { c.Status(200) })
+	router.GET("/health", limiter.For("health"), func(c *gin.Context) { c.Status(200) })
+	return router
+}
+
+func doRateLimitedRequest(router *gin.Engine, path, remoteAddr, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("requests over the limit are rejected", func(t *testing.T) {
+		router := newRateLimitTestRouter(t, nil)
+
+		for i, remaining := range []string{"1", "0"} {
+			w := doRateLimitedRequest(router, "/users", "10.0.0.1:1234", "")
+			if w.Code != 200 || w.Header().Get("RateLimit-Remaining") != remaining {
+				t.Fatalf("request %d: expected 200 with %s remaining, got %d with %s", i+1, remaining, w.Code, w.Header().Get("RateLimit-Remaining"))
+			}
+		}
+
+		w := doRateLimitedRequest(router, "/users", "10.0.0.1:1234", "")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", w.Code)
+		}
+
+		if w.Header().Get("Retry-After") == "" || w.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("expected rate limit headers, got %v", w.Header())
+		}
+	})
+
+	t.Run("authenticated users and IPs are counted separately", func(t *testing.T) {
+		router := newRateLimitTestRouter(t, nil)
+
+		for range 2 {
+			doRateLimitedRequest(router, "/users", "10.0.0.1:1234", "user-1")
+		}
+
+		if w := doRateLimitedRequest(router, "/users", "10.0.0.1:1234", "user-2"); w.Code != 200 {
+			t.Errorf("expected another user to pass, got %d", w.Code)
+		}
+
+		if w := doRateLimitedRequest(router, "/users", "10.0.0.1:1234", "invalid"); w.Code != 200 {
+			t.Errorf("expected an invalid token to be counted by IP, got %d", w.Code)
+		}
+
+		if w := doRateLimitedRequest(router, "/users", "10.0.0.1:1234", "user-1"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("expected user-1 to be limited, got %d", w.Code)
+		}
+	})
+
+	t.Run("allowlisted callers and unlimited groups pass", func(t *testing.T) {
+		router := newRateLimitTestRouter(t, []string{"192.168.0.0/16"})
+
+		for range 5 {
+			if w := doRateLimitedRequest(router, "/users", "192.168.1.10:1234", ""); w.Code != 200 {
+				t.Fatalf("expected allowlisted caller to pass, got %d", w.Code)
+			}
+
+			if w := doRateLimitedRequest(router, "/health", "10.0.0.1:1234", ""); w.Code != 200 {
+				t.Fatalf("expected unlimited group to pass, got %d", w.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"regexp"
+
+	"codetest/internal/requestctx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID keeps client supplied IDs short and safe to log.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestIDMiddleware reuses the X-Request-ID sent by the client, or a proxy,
+// when it looks sane and generates one otherwise. The ID is echoed in the
+// response and stored in the request context for logs and audit events.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Request = ctx.Request.WithContext(requestctx.WithRequestID(ctx.Request.Context(), requestID))
+		ctx.Header(RequestIDHeader, requestID)
+		trace.SpanFromContext(ctx.Request.Context()).SetAttributes(attribute.String("http.request_id", requestID))
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"codetest/internal/requestctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.GET("/", func(c *gin.Context) {
+		c.String(200, requestctx.RequestID(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name      string
+		header    string
+		keepsSent bool
+	}{
+		{name: "valid ID is reused", header: "abc-123", keepsSent: true},
+		{name: "missing ID is generated"},
+		{name: "unsafe ID is replaced", header: "bad id\nwith newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			echoed := w.Header().Get(RequestIDHeader)
+			if echoed == "" || echoed != w.Body.String() {
+				t.Fatalf("expected the context ID %q to be echoed, got %q", w.Body.String(), echoed)
+			}
+
+			if (echoed == tt.header) != tt.keepsSent {
+				t.Errorf("unexpected request ID %q for header %q", echoed, tt.header)
+			}
+		})
+	}
+}
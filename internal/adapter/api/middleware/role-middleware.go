@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"codetest/internal/adapter/api/presenter"
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RoleMiddleware only lets through users holding one of roles. It must run
+// after AccessTokenMiddleware.
+func RoleMiddleware(userService portservice.UserService, roles ...model.UserRole) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userId, err := uuid.Parse(ctx.GetString("userId"))
+		if err != nil {
+			ctx.JSON(401, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Error:   "Unauthorized",
+			})
+			ctx.Abort()
+			return
+		}
+
+		user, err := userService.GetOneByID(ctx, userId)
+		if err != nil {
+			ctx.JSON(401, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Error:   "Unauthorized",
+			})
+			ctx.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+
+		ctx.JSON(403, presenter.JsonResponseWithoutPagination{
+			Success: false,
+			Error:   "Forbidden",
+		})
+		ctx.Abort()
+	}
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"codetest/internal/adapter/api/dto"
 	"codetest/internal/adapter/api/presenter"
+	"codetest/internal/query"
+	"net/url"
 	"reflect"
 
 	"github.com/gin-gonic/gin"
@@ -11,6 +14,12 @@ import (
 
 type BindingType int
 
+// queryParser is implemented by list requests that accept the filter[...] and
+// sort query language.
+type queryParser interface {
+	ParseQuery(values url.Values) error
+}
+
 const (
 	BindJSON BindingType = iota
 	BindQuery
@@ -33,30 +42,35 @@ func ValidationMiddleware(T any, bindingType BindingType) gin.HandlerFunc {
 		val := reflect.New(tType.Elem()).Interface()
 
 		if err := getBindingError(c, val, bindingType); err != nil {
-			if errors, ok := err.(validator.ValidationErrors); ok {
-				errorMessages := make(map[string]string)
-				for _, e := range errors {
-					field, _ := tType.Elem().FieldByName(e.Field())
-					jsonTag := field.Tag.Get(getTagName(bindingType))
-					errorMessages[jsonTag] = formatValidationMessages(jsonTag, e.Tag(), e.Param())
+			c.JSON(400, presenter.JsonResponseWithoutPagination{
+				Success: false,
+				Data:    nil,
+				Error:   ValidationErrorMessages(val, err, bindingType),
+			})
+			c.Abort()
+			return
+		}
+
+		if parser, ok := val.(queryParser); ok {
+			if err := parser.ParseQuery(c.Request.URL.Query()); err != nil {
+				if errors, ok := err.(query.Errors); ok {
+					c.JSON(400, presenter.JsonResponseWithoutPagination{
+						Success: false,
+						Data:    nil,
+						Error:   errors,
+					})
+					c.Abort()
+					return
 				}
 
 				c.JSON(400, presenter.JsonResponseWithoutPagination{
 					Success: false,
 					Data:    nil,
-					Error:   errorMessages,
+					Error:   err.Error(),
 				})
 				c.Abort()
 				return
 			}
-
-			c.JSON(400, presenter.JsonResponseWithoutPagination{
-				Success: false,
-				Data:    nil,
-				Error:   err.Error(),
-			})
-			c.Abort()
-			return
 		}
 
 		c.Set("validatedRequest", val)
@@ -64,6 +78,30 @@ func ValidationMiddleware(T any, bindingType BindingType) gin.HandlerFunc {
 	}
 }
 
+// ValidationErrorMessages turns a binding or validation error for val into
+// the field => message map returned to clients, or the plain error text for
+// anything that is not a validation error.
+func ValidationErrorMessages(val any, err error, bindingType BindingType) interface{} {
+	errors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err.Error()
+	}
+
+	tType := reflect.TypeOf(val)
+	if tType.Kind() == reflect.Ptr {
+		tType = tType.Elem()
+	}
+
+	errorMessages := make(map[string]string)
+	for _, e := range errors {
+		field, _ := tType.FieldByName(e.Field())
+		jsonTag := field.Tag.Get(getTagName(bindingType))
+		errorMessages[jsonTag] = dto.ValidationMessage(jsonTag, e.Tag(), e.Param())
+	}
+
+	return errorMessages
+}
+
 func getBindingError(c *gin.Context, val any, bindingType BindingType) error {
 	var err error
 
@@ -105,42 +143,3 @@ func getTagName(bindingType BindingType) string {
 
 	return tagName
 }
-
-func formatValidationMessages(field, tag, param string) string {
-	switch tag {
-	case "required":
-		return field + " is required"
-	case "email":
-		return field + " must be a valid email address"
-	case "min":
-		return field + " must be at least " + param + " characters long"
-	case "max":
-		return field + " must be at most " + param + " characters long"
-	case "gte":
-		return field + " must be greater than or equal to " + param
-	case "lte":
-		return field + " must be less than or equal to " + param
-	case "eq":
-		return field + " must be equal to " + param
-	case "ne":
-		return field + " must not be equal to " + param
-	case "gt":
-		return field + " must be greater than " + param
-	case "lt":
-		return field + " must be less than " + param
-	case "len":
-		return field + " must be exactly " + param + " characters long"
-	case "oneof":
-		return field + " must be one of the following values: " + param
-	case "notoneof":
-		return field + " must not be one of the following values: " + param
-	case "url":
-		return field + " must be a valid URL"
-	case "uuid":
-		return field + " must be a valid UUID"
-	case "json":
-		return field + " must be a valid JSON"
-	default:
-		return field + " is invalid"
-	}
-}
@@ -0,0 +1,53 @@
+package util
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrMissingIfMatch = errors.New("If-Match header is required")
+	ErrInvalidIfMatch = errors.New("If-Match header must be an ETag returned by the API")
+	ErrWeakIfMatch    = errors.New("If-Match uses strong comparison, weak ETags never match")
+)
+
+// AnyVersion is returned for "If-Match: *", which matches whatever version
+// the user currently has.
+const AnyVersion = 0
+
+func FormatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// GetVersionFromIfMatch reads the row version from the If-Match header.
+// If-Match compares strongly (RFC 9110 section 13.1.1), so weak validators
+// are reported with ErrWeakIfMatch rather than accepted.
+func GetVersionFromIfMatch(ctx *gin.Context) (int, error) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		return 0, ErrMissingIfMatch
+	}
+
+	if header == "*" {
+		return AnyVersion, nil
+	}
+
+	if strings.HasPrefix(header, "W/") {
+		return 0, ErrWeakIfMatch
+	}
+
+	tag := header
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, ErrInvalidIfMatch
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil || version < 1 {
+		return 0, ErrInvalidIfMatch
+	}
+
+	return version, nil
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetVersionFromIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		version int
+		err     error
+	}{
+		{header: `"3"`, version: 3},
+		{header: ` "12" `, version: 12},
+		{header: "*", version: AnyVersion},
+		{header: "", err: ErrMissingIfMatch},
+		{header: `W/"3"`, err: ErrWeakIfMatch},
+		{header: "3", err: ErrInvalidIfMatch},
+		{header: `"0"`, err: ErrInvalidIfMatch},
+		{header: `"abc"`, err: ErrInvalidIfMatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest("PUT", "/users/1", nil)
+			ctx.Request.Header.Set("If-Match", tt.header)
+
+			version, err := GetVersionFromIfMatch(ctx)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("expected error %v, got %v", tt.err, err)
+			}
+			if version != tt.version {
+				t.Errorf("expected version %d, got %d", tt.version, version)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package util
+
+import (
+	"errors"
+	"mime"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+var ErrUnsupportedPatchType = errors.New("Content-Type must be " + MergePatchContentType + " or " + JSONPatchContentType)
+
+// ApplyPatch applies patch to the JSON document doc, using RFC 7396 merge
+// patch semantics for application/merge-patch+json (and plain
+// application/json) and RFC 6902 for application/json-patch+json.
+func ApplyPatch(doc, patch []byte, contentType string) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, ErrUnsupportedPatchType
+	}
+
+	switch mediaType {
+	case MergePatchContentType, "application/json":
+		return jsonpatch.MergePatch(doc, patch)
+	case JSONPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, err
+		}
+		return operations.Apply(doc)
+	default:
+		return nil, ErrUnsupportedPatchType
+	}
+}
@@ -0,0 +1,77 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	doc := []byte(`{"name":"John Doe","email":"john@doe.com"}`)
+
+	tests := []struct {
+		name          string
+		patch         string
+		contentType   string
+		expected      map[string]interface{}
+		expectedError error
+	}{
+		{
+			name:        "merge patch replaces a field and keeps the rest",
+			patch:       `{"name":"Jane Doe"}`,
+			contentType: MergePatchContentType,
+			expected:    map[string]interface{}{"name": "Jane Doe", "email": "john@doe.com"},
+		},
+		{
+			name:        "merge patch null removes a field",
+			patch:       `{"email":null}`,
+			contentType: MergePatchContentType + "; charset=utf-8",
+			expected:    map[string]interface{}{"name": "John Doe"},
+		},
+		{
+			name:        "plain json is treated as merge patch",
+			patch:       `{"password":"secret123"}`,
+			contentType: "application/json",
+			expected:    map[string]interface{}{"name": "John Doe", "email": "john@doe.com", "password": "secret123"},
+		},
+		{
+			name:        "json patch operations are applied in order",
+			patch:       `[{"op":"test","path":"/name","value":"John Doe"},{"op":"replace","path":"/email","value":"j@doe.com"}]`,
+			contentType: JSONPatchContentType,
+			expected:    map[string]interface{}{"name": "John Doe", "email": "j@doe.com"},
+		},
+		{
+			name:          "unsupported content type is rejected",
+			patch:         `name=Jane`,
+			contentType:   "application/x-www-form-urlencoded",
+			expectedError: ErrUnsupportedPatchType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patched, err := ApplyPatch(doc, []byte(tt.patch), tt.contentType)
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var result map[string]interface{}
+			if err := json.Unmarshal(patched, &result); err != nil {
+				t.Fatalf("patched document is not valid JSON: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
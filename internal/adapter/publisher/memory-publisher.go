@@ -0,0 +1,47 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"codetest/internal/model"
+)
+
+// MemoryPublisher hands events to its subscribers synchronously, within the
+// process. It stands in for the Redis channel where there is a single
+// consumer, like the CLI or tests.
+type MemoryPublisher struct {
+	mu          sync.RWMutex
+	subscribers []func(ctx context.Context, userLog *model.UserLogModel) error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Subscribe registers fn to receive every event published from now on.
+func (p *MemoryPublisher) Subscribe(fn func(ctx context.Context, userLog *model.UserLogModel) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.subscribers = append(p.subscribers, fn)
+}
+
+// Publish calls every subscriber with its own copy of userLog and joins
+// their errors.
+func (p *MemoryPublisher) Publish(ctx context.Context, userLog *model.UserLogModel) error {
+	p.mu.RLock()
+	subscribers := p.subscribers
+	p.mu.RUnlock()
+
+	var errs []error
+	for _, subscriber := range subscribers {
+		event := *userLog
+		if err := subscriber(ctx, &event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
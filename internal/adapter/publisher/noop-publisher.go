@@ -0,0 +1,19 @@
+package publisher
+
+import (
+	"context"
+
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+)
+
+type noopPublisher struct{}
+
+// NewNoopPublisher drops every event, for deployments not keeping user logs.
+func NewNoopPublisher() portservice.EventPublisher {
+	return noopPublisher{}
+}
+
+func (noopPublisher) Publish(context.Context, *model.UserLogModel) error {
+	return nil
+}
@@ -0,0 +1,40 @@
+// Package publisher implements portservice.EventPublisher and the helper
+// every audited code path emits its user log events with.
+package publisher
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"codetest/internal/logger"
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+	"codetest/internal/requestctx"
+	"codetest/internal/telemetry"
+)
+
+// Emit publishes event on behalf of the actor of ctx, carrying its request
+// ID, background job and trace context. A failure is logged, it does not undo
+// the change being audited.
+func Emit(ctx context.Context, events portservice.EventPublisher, event model.UserLogEvent, data map[string]interface{}) {
+	if jobID := requestctx.JobID(ctx); jobID != "" {
+		if data == nil {
+			data = map[string]interface{}{}
+		}
+		data["job_id"] = jobID
+	}
+
+	userLog := &model.UserLogModel{
+		UserID:       requestctx.Actor(ctx),
+		Event:        event,
+		Data:         data,
+		RequestID:    requestctx.RequestID(ctx),
+		TraceContext: telemetry.Inject(ctx),
+		CreatedAt:    time.Now(),
+	}
+
+	if err := events.Publish(ctx, userLog); err != nil {
+		slog.ErrorContext(ctx, "Failed to publish user log event", slog.String("event", event.String()), logger.Error(err))
+	}
+}
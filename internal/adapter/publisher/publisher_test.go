@@ -0,0 +1,70 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"codetest/internal/model"
+	"codetest/internal/requestctx"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestEmit(t *testing.T) {
+	var published []*model.UserLogModel
+	events := NewMemoryPublisher()
+	events.Subscribe(func(ctx context.Context, userLog *model.UserLogModel) error {
+		published = append(published, userLog)
+		return nil
+	})
+	events.Subscribe(func(ctx context.Context, userLog *model.UserLogModel) error {
+		return errors.New("sink down")
+	})
+
+	ctx := requestctx.WithJobID(requestctx.WithActor(context.Background(), "user-1"), "job-1")
+	Emit(ctx, events, model.UserLogEventExport, map[string]interface{}{"count": 3})
+
+	if len(published) != 1 {
+		t.Fatalf("expected 1 event despite the failing subscriber, got %d", len(published))
+	}
+
+	event := published[0]
+	data := event.Data.(map[string]interface{})
+	if event.UserID != "user-1" || event.Event != model.UserLogEventExport || data["job_id"] != "job-1" || data["count"] != 3 {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestRedisPublisher(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	subscriber := client.Subscribe(ctx, "user_log_channel")
+	defer subscriber.Close()
+	if _, err := subscriber.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	events := NewRedisPublisher(client, "user_log_channel")
+	if err := events.Publish(ctx, &model.UserLogModel{UserID: "user-1", Event: model.UserLogEventCreate}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	msg, err := subscriber.ReceiveMessage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var userLog model.UserLogModel
+	if err := json.Unmarshal([]byte(msg.Payload), &userLog); err != nil {
+		t.Fatal(err)
+	}
+	if userLog.UserID != "user-1" || userLog.Event != model.UserLogEventCreate {
+		t.Errorf("unexpected event %+v", userLog)
+	}
+}
@@ -0,0 +1,40 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+
+	"codetest/internal/metrics"
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisPublisher struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisPublisher publishes events as JSON on a Redis channel, consumed by
+// the subscriber of every server replica.
+func NewRedisPublisher(client *redis.Client, channel string) portservice.EventPublisher {
+	return &redisPublisher{
+		client:  client,
+		channel: channel,
+	}
+}
+
+func (p *redisPublisher) Publish(ctx context.Context, userLog *model.UserLogModel) error {
+	payload, err := json.Marshal(userLog)
+	if err != nil {
+		return err
+	}
+
+	if err := p.client.Publish(ctx, p.channel, payload).Err(); err != nil {
+		metrics.RedisPublishFailures.WithLabelValues(p.channel).Inc()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"codetest/internal/logger"
+	"codetest/internal/metrics"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	"codetest/internal/requestctx"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// cachedUser mirrors model.UserModel without the password hash, which never
+// leaves the database.
+type cachedUser struct {
+	ID        uuid.UUID      `json:"id"`
+	Name      string         `json:"name"`
+	Email     string         `json:"email"`
+	Role      model.UserRole `json:"role"`
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+}
+
+// setIfGenerationScript caches a user only if its generation did not change
+// since the fill started, so an invalidation racing with the fill wins.
+var setIfGenerationScript = redis.NewScript(`
+if (redis.call("GET", KEYS[2]) or "0") == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return 0`)
+
+// userRepository is a read-through cache in front of another UserRepository.
+// Active users are cached under their ID without their password hash, lookups
+// by email are used to log in and go to the database. Every other method is
+// delegated as is.
+type userRepository struct {
+	portrepository.UserRepository
+
+	redisClient *redis.Client
+	ttl         time.Duration
+	group       singleflight.Group
+}
+
+func NewUserRepository(next portrepository.UserRepository, redisClient *redis.Client, ttl time.Duration) portrepository.UserRepository {
+	return &userRepository{
+		UserRepository: next,
+		redisClient:    redisClient,
+		ttl:            ttl,
+	}
+}
+
+// GetOneBy serves lookups by id from the cache, concurrent misses for the
+// same user share a single database query. Users looked up by id come
+// without their password hash, whether cached or not.
+func (u *userRepository) GetOneBy(ctx context.Context, column string, value string) (*model.UserModel, error) {
+	if column != "id" {
+		return u.UserRepository.GetOneBy(ctx, column, value)
+	}
+
+	// The cache may have been filled from a replica lagging behind.
+	if requestctx.PrimaryRead(ctx) {
+		stored, err := u.UserRepository.GetOneBy(ctx, column, value)
+		if err != nil {
+			return nil, err
+		}
+		user := *stored
+		user.Password = ""
+		return &user, nil
+	}
+
+	if user := u.get(ctx, value); user != nil {
+		metrics.UserCacheHits.WithLabelValues(column).Inc()
+		return user, nil
+	}
+	metrics.UserCacheMisses.WithLabelValues(column).Inc()
+
+	result, err, _ := u.group.Do(value, func() (interface{}, error) {
+		// The query must not fail for every waiting caller because the
+		// first one went away.
+		ctx := context.WithoutCancel(ctx)
+
+		generation, err := u.redisClient.Get(ctx, generationKey(value)).Result()
+		if errors.Is(err, redis.Nil) {
+			generation = "0"
+		}
+		cacheable := err == nil || errors.Is(err, redis.Nil)
+
+		user, err := u.UserRepository.GetOneBy(ctx, column, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if cacheable {
+			u.set(ctx, user, generation)
+		}
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers may modify the user, each gets its own copy.
+	user := *result.(*model.UserModel)
+	user.Password = ""
+	return &user, nil
+}
+
+func (u *userRepository) Update(ctx context.Context, user *model.UserModel) error {
+	if err := u.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	u.invalidate(ctx, "id", user.ID.String())
+	return nil
+}
+
+func (u *userRepository) UpdateColumnsBy(ctx context.Context, column string, value string, values map[string]interface{}) error {
+	if err := u.UserRepository.UpdateColumnsBy(ctx, column, value, values); err != nil {
+		return err
+	}
+
+	u.invalidate(ctx, column, value)
+	return nil
+}
+
+func (u *userRepository) DeleteOneBy(ctx context.Context, column string, value string) error {
+	if err := u.UserRepository.DeleteOneBy(ctx, column, value); err != nil {
+		return err
+	}
+
+	u.invalidate(ctx, column, value)
+	return nil
+}
+
+func (u *userRepository) RestoreOneBy(ctx context.Context, column string, value string) error {
+	if err := u.UserRepository.RestoreOneBy(ctx, column, value); err != nil {
+		return err
+	}
+
+	u.invalidate(ctx, column, value)
+	return nil
+}
+
+func (u *userRepository) PurgeOneBy(ctx context.Context, column string, value string) error {
+	if err := u.UserRepository.PurgeOneBy(ctx, column, value); err != nil {
+		return err
+	}
+
+	u.invalidate(ctx, column, value)
+	return nil
+}
+
+func (u *userRepository) get(ctx context.Context, id string) *model.UserModel {
+	payload, err := u.redisClient.Get(ctx, idKey(id)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			slog.WarnContext(ctx, "Failed to read user cache", logger.Error(err))
+		}
+		return nil
+	}
+
+	var cached cachedUser
+	if err := json.Unmarshal(payload, &cached); err != nil {
+		return nil
+	}
+
+	return &model.UserModel{
+		ID:        cached.ID,
+		Name:      cached.Name,
+		Email:     cached.Email,
+		Role:      cached.Role,
+		Version:   cached.Version,
+		CreatedAt: cached.CreatedAt,
+		UpdatedAt: cached.UpdatedAt,
+
+		DisabledAt: cached.DisabledAt,
+	}
+}
+
+// set caches user unless it was invalidated since generation was read.
+func (u *userRepository) set(ctx context.Context, user *model.UserModel, generation string) {
+	payload, err := json.Marshal(cachedUser{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Role:      user.Role,
+		Version:   user.Version,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+
+		DisabledAt: user.DisabledAt,
+	})
+	if err != nil {
+		return
+	}
+
+	id := user.ID.String()
+	if err := setIfGenerationScript.Run(ctx, u.redisClient, []string{idKey(id), generationKey(id)}, generation, payload, u.ttl.Milliseconds()).Err(); err != nil && !errors.Is(err, redis.Nil) {
+		slog.WarnContext(ctx, "Failed to write user cache", logger.Error(err))
+	}
+}
+
+// invalidate drops the cached user matching column and value and bumps its
+// generation, so a fill already in flight does not cache it again. Lookups by
+// any other column than id are not cached.
+func (u *userRepository) invalidate(ctx context.Context, column, value string) {
+	if column != "id" {
+		return
+	}
+
+	u.group.Forget(value)
+
+	_, err := u.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, idKey(value))
+		pipe.Incr(ctx, generationKey(value))
+		// Outliving any fill is enough, an expired generation reads as 0.
+		pipe.Expire(ctx, generationKey(value), u.ttl)
+		return nil
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to invalidate user cache", logger.Error(err))
+	}
+}
+
+func idKey(id string) string {
+	return "user:id:" + id
+}
+
+func generationKey(id string) string {
+	return "user:generation:" + id
+}
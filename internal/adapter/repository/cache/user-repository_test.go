@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codetest/internal/model"
+	"codetest/internal/requestctx"
+	"codetest/mocks/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestUserRepository(t *testing.T) (*repository.MockUserRepository, *userRepository) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return mockUserRepo, NewUserRepository(mockUserRepo, client, time.Minute).(*userRepository)
+}
+
+func TestUserRepository_GetOneBy(t *testing.T) {
+	ctx := context.Background()
+	user := &model.UserModel{ID: uuid.New(), Name: "John Doe", Email: "john@doe.com", Password: "hash", Version: 1}
+
+	t.Run("second lookup is served from the cache", func(t *testing.T) {
+		mockUserRepo, cachedRepo := newTestUserRepository(t)
+		mockUserRepo.EXPECT().GetOneBy(gomock.Any(), "id", user.ID.String()).Return(user, nil).Times(1)
+
+		for range 2 {
+			got, err := cachedRepo.GetOneBy(ctx, "id", user.ID.String())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got.Email != user.Email || got.Password != "" {
+				t.Errorf("expected cached user without password hash, got %+v", got)
+			}
+		}
+
+		payload, err := cachedRepo.redisClient.Get(ctx, idKey(user.ID.String())).Result()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if strings.Contains(payload, user.Password) {
+			t.Errorf("expected the password hash not to be cached, got %s", payload)
+		}
+	})
+
+	t.Run("primary lookup skips the cache", func(t *testing.T) {
+		mockUserRepo, cachedRepo := newTestUserRepository(t)
+		mockUserRepo.EXPECT().GetOneBy(gomock.Any(), "id", user.ID.String()).Return(user, nil).Times(3)
+
+		if _, err := cachedRepo.GetOneBy(ctx, "id", user.ID.String()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for range 2 {
+			got, err := cachedRepo.GetOneBy(requestctx.WithPrimaryRead(ctx), "id", user.ID.String())
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got.Email != user.Email || got.Password != "" {
+				t.Errorf("expected the user without password hash, got %+v", got)
+			}
+		}
+		if user.Password != "hash" {
+			t.Errorf("expected the stored user to be left alone, got %+v", user)
+		}
+	})
+
+	t.Run("lookup by email goes to the database", func(t *testing.T) {
+		mockUserRepo, cachedRepo := newTestUserRepository(t)
+		mockUserRepo.EXPECT().GetOneBy(gomock.Any(), "email", user.Email).Return(user, nil).Times(2)
+
+		for range 2 {
+			got, err := cachedRepo.GetOneBy(ctx, "email", user.Email)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if got.Password != user.Password {
+				t.Errorf("expected the user with its password hash, got %+v", got)
+			}
+		}
+	})
+
+	t.Run("update invalidates the cached user", func(t *testing.T) {
+		mockUserRepo, cachedRepo := newTestUserRepository(t)
+		updated := *user
+		updated.Email = "jane@doe.com"
+		updated.Version = 2
+
+		gomock.InOrder(
+			mockUserRepo.EXPECT().GetOneBy(gomock.Any(), "id", user.ID.String()).Return(user, nil),
+			mockUserRepo.EXPECT().Update(gomock.Any(), &updated).Return(nil),
+			mockUserRepo.EXPECT().GetOneBy(gomock.Any(), "id", user.ID.String()).Return(&updated, nil),
+		)
+
+		if _, err := cachedRepo.GetOneBy(ctx, "id", user.ID.String()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if err := cachedRepo.Update(ctx, &updated); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got, err := cachedRepo.GetOneBy(ctx, "id", user.ID.String())
+		if err != nil || got.Version != 2 {
+			t.Fatalf("expected the updated user, got %+v (%v)", got, err)
+		}
+	})
+
+	t.Run("invalidation during a fill wins", func(t *testing.T) {
+		mockUserRepo, cachedRepo := newTestUserRepository(t)
+
+		// The user is updated while the miss is still reading the old row.
+		mockUserRepo.EXPECT().GetOneBy(gomock.Any(), "id", user.ID.String()).DoAndReturn(func(ctx context.Context, column, value string) (*model.UserModel, error) {
+			cachedRepo.invalidate(ctx, column, value)
+			return user, nil
+		})
+
+		if _, err := cachedRepo.GetOneBy(ctx, "id", user.ID.String()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if exists, err := cachedRepo.redisClient.Exists(ctx, idKey(user.ID.String())).Result(); err != nil || exists != 0 {
+			t.Errorf("expected the stale user not to be cached, got %d (%v)", exists, err)
+		}
+	})
+
+	t.Run("concurrent misses share one query", func(t *testing.T) {
+		mockUserRepo, cachedRepo := newTestUserRepository(t)
+		mockUserRepo.EXPECT().GetOneBy(gomock.Any(), "id", user.ID.String()).DoAndReturn(func(ctx context.Context, column, value string) (*model.UserModel, error) {
+			time.Sleep(100 * time.Millisecond)
+			return user, nil
+		}).Times(1)
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := cachedRepo.GetOneBy(ctx, "id", user.ID.String()); err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+}
@@ -0,0 +1,48 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+)
+
+// userLogRepository writes every user log to all of its repositories and
+// reads from the first one supporting Find.
+type userLogRepository struct {
+	repositories []portrepository.UserLogRepository
+}
+
+func NewUserLogRepository(repositories ...portrepository.UserLogRepository) portrepository.UserLogRepository {
+	return &userLogRepository{
+		repositories: repositories,
+	}
+}
+
+// Create tries every repository even when one fails, so a sink being down
+// does not cost the others the event.
+func (u *userLogRepository) Create(ctx context.Context, userLog *model.UserLogModel) error {
+	var errs []error
+	for _, repository := range u.repositories {
+		if err := repository.Create(ctx, userLog); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (u *userLogRepository) Find(ctx context.Context, request *dto.QueryUserLogRequest) ([]*model.UserLogModel, int64, error) {
+	for _, repository := range u.repositories {
+		userLogs, total, err := repository.Find(ctx, request)
+		if errors.Is(err, portrepository.ErrNotSupported) {
+			continue
+		}
+
+		return userLogs, total, err
+	}
+
+	return nil, 0, portrepository.ErrNotSupported
+}
@@ -0,0 +1,57 @@
+package fanout
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	"codetest/mocks/repository"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestUserLogRepository(t *testing.T) {
+	ctx := context.Background()
+	userLog := &model.UserLogModel{UserID: "user-1", Event: model.UserLogEventCreate}
+	request := &dto.QueryUserLogRequest{Page: 1, PageSize: 10}
+
+	t.Run("create reaches every repository despite failures", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		failing := repository.NewMockUserLogRepository(ctrl)
+		working := repository.NewMockUserLogRepository(ctrl)
+
+		failing.EXPECT().Create(ctx, userLog).Return(errors.New("down"))
+		working.EXPECT().Create(ctx, userLog).Return(nil)
+
+		if err := NewUserLogRepository(failing, working).Create(ctx, userLog); err == nil {
+			t.Error("expected the failure to be reported")
+		}
+	})
+
+	t.Run("find skips write only repositories", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		writeOnly := repository.NewMockUserLogRepository(ctrl)
+		readable := repository.NewMockUserLogRepository(ctrl)
+
+		writeOnly.EXPECT().Find(ctx, request).Return(nil, int64(0), portrepository.ErrNotSupported)
+		readable.EXPECT().Find(ctx, request).Return([]*model.UserLogModel{userLog}, int64(1), nil)
+
+		userLogs, total, err := NewUserLogRepository(writeOnly, readable).Find(ctx, request)
+		if err != nil || total != 1 || len(userLogs) != 1 {
+			t.Errorf("expected the readable repository to answer, got %v, %d, %v", userLogs, total, err)
+		}
+	})
+
+	t.Run("find fails when nothing is readable", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		writeOnly := repository.NewMockUserLogRepository(ctrl)
+		writeOnly.EXPECT().Find(ctx, request).Return(nil, int64(0), portrepository.ErrNotSupported)
+
+		if _, _, err := NewUserLogRepository(writeOnly).Find(ctx, request); !errors.Is(err, portrepository.ErrNotSupported) {
+			t.Errorf("expected ErrNotSupported, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+)
+
+// backupTimeFormat sorts lexically in chronological order.
+const backupTimeFormat = "20060102T150405.000000000"
+
+// userLogRepository appends user logs as NDJSON to path. Once the file would
+// grow past maxSize it is renamed with a timestamp suffix and a new one is
+// started, keeping at most maxBackups renamed files.
+type userLogRepository struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewUserLogRepository(path string, maxSize int64, maxBackups int) (portrepository.UserLogRepository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	u := &userLogRepository{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := u.open(); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+func (u *userLogRepository) Create(ctx context.Context, userLog *model.UserLogModel) error {
+	line, err := json.Marshal(userLog)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.size > 0 && u.size+int64(len(line)) > u.maxSize {
+		if err := u.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := u.file.Write(line)
+	u.size += int64(n)
+	return err
+}
+
+// Find is not supported, the files are meant for log shippers.
+func (u *userLogRepository) Find(ctx context.Context, request *dto.QueryUserLogRequest) ([]*model.UserLogModel, int64, error) {
+	return nil, 0, portrepository.ErrNotSupported
+}
+
+func (u *userLogRepository) open() error {
+	file, err := os.OpenFile(u.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	u.file = file
+	u.size = info.Size()
+	return nil
+}
+
+func (u *userLogRepository) rotate() error {
+	if err := u.file.Close(); err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(u.path)
+	base := strings.TrimSuffix(u.path, ext)
+	renameErr := os.Rename(u.path, base+"-"+time.Now().UTC().Format(backupTimeFormat)+ext)
+
+	// Reopen even when the rename failed so later events can still be written.
+	if err := u.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	backups, err := filepath.Glob(globEscape(base) + "-*" + globEscape(ext))
+	if err != nil {
+		return err
+	}
+	slices.Sort(backups)
+
+	for len(backups) > u.maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+
+	return nil
+}
+
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+
+func globEscape(path string) string {
+	return globEscaper.Replace(path)
+}
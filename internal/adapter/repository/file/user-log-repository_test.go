@@ -0,0 +1,78 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+)
+
+func readLines(t *testing.T, path string) []model.UserLogModel {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var userLogs []model.UserLogModel
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var userLog model.UserLogModel
+		if err := json.Unmarshal(scanner.Bytes(), &userLog); err != nil {
+			t.Fatalf("expected NDJSON, got %q: %v", scanner.Text(), err)
+		}
+		userLogs = append(userLogs, userLog)
+	}
+
+	return userLogs
+}
+
+func TestUserLogRepository_Create(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "logs", "user-logs.ndjson")
+
+	// Lines are about 100 bytes, so each event ends up in a file of its own.
+	repo, err := NewUserLogRepository(path, 150, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 7 {
+		err := repo.Create(ctx, &model.UserLogModel{
+			UserID:    "user-1",
+			Event:     model.UserLogEventUpdate,
+			Data:      map[string]interface{}{"n": i},
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	current := readLines(t, path)
+	if len(current) != 1 || current[0].Event != model.UserLogEventUpdate {
+		t.Errorf("expected the last event in the current file, got %+v", current)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(filepath.Dir(path), "user-logs-*.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("expected 2 backups to be kept, got %v", backups)
+	}
+
+	if _, _, err := repo.Find(ctx, &dto.QueryUserLogRequest{}); !errors.Is(err, portrepository.ErrNotSupported) {
+		t.Errorf("expected ErrNotSupported, got %v", err)
+	}
+}
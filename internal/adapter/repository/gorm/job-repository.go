@@ -0,0 +1,195 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
+)
+
+type jobRepository struct {
+	DB *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) portrepository.JobRepository {
+	return &jobRepository{
+		DB: db,
+	}
+}
+
+func (j *jobRepository) Create(ctx context.Context, job *model.JobModel) error {
+	return j.DB.WithContext(ctx).Create(job).Error
+}
+
+// GetOneBy reads from the primary, a job polled right after being enqueued
+// may not have reached the replicas yet.
+func (j *jobRepository) GetOneBy(ctx context.Context, column string, value string) (*model.JobModel, error) {
+	var job model.JobModel
+	if err := j.DB.WithContext(ctx).Clauses(dbresolver.Write).Where(column+" = ?", value).First(&job).Error; err != nil {
+		return nil, translateError(err)
+	}
+
+	return &job, nil
+}
+
+// ClaimNext relies on FOR UPDATE SKIP LOCKED so concurrent workers, in this
+// process or another replica, never claim the same job.
+func (j *jobRepository) ClaimNext(ctx context.Context, staleBefore time.Time) (*model.JobModel, error) {
+	if !isPostgres(j.DB) {
+		return j.claimNextInTransaction(ctx, staleBefore)
+	}
+
+	var jobs []*model.JobModel
+
+	err := j.DB.WithContext(ctx).Raw(`
+		UPDATE jobs SET status = ?, attempts = attempts + 1, started_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = ? OR (status = ? AND updated_at < ?)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		model.JobStatusRunning, model.JobStatusQueued, model.JobStatusRunning, staleBefore,
+	).Scan(&jobs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+
+	return jobs[0], nil
+}
+
+// claimNextInTransaction is ClaimNext without UPDATE ... RETURNING. MySQL
+// still skips the rows locked by other workers, SQLite has a single
+// connection so transactions never overlap.
+func (j *jobRepository) claimNextInTransaction(ctx context.Context, staleBefore time.Time) (*model.JobModel, error) {
+	var job *model.JobModel
+
+	err := j.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&model.JobModel{}).
+			Where("status = ? OR (status = ? AND updated_at < ?)", model.JobStatusQueued, model.JobStatusRunning, staleBefore).
+			Order("created_at").
+			Limit(1)
+		if tx.Dialector.Name() == "mysql" {
+			query = query.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked})
+		}
+
+		var ids []string
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.Model(&model.JobModel{}).Where("id = ?", ids[0]).Updates(map[string]interface{}{
+			"status":     model.JobStatusRunning,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"started_at": now,
+			"updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		job = &model.JobModel{}
+		return tx.First(job, "id = ?", ids[0]).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (j *jobRepository) Heartbeat(ctx context.Context, job *model.JobModel) (bool, error) {
+	if !isPostgres(j.DB) {
+		return j.heartbeatInTransaction(ctx, job)
+	}
+
+	var cancelRequested []bool
+
+	err := j.DB.WithContext(ctx).Raw(`
+		UPDATE jobs SET progress = ?, total = ?, updated_at = NOW()
+		WHERE id = ?
+		RETURNING cancel_requested`,
+		job.Progress, job.Total, job.ID,
+	).Scan(&cancelRequested).Error
+	if err != nil {
+		return false, err
+	}
+
+	if len(cancelRequested) == 0 {
+		return false, portrepository.ErrNotFound
+	}
+
+	return cancelRequested[0], nil
+}
+
+func (j *jobRepository) heartbeatInTransaction(ctx context.Context, job *model.JobModel) (bool, error) {
+	var cancelRequested []bool
+
+	err := j.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.JobModel{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+			"progress":   job.Progress,
+			"total":      job.Total,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.JobModel{}).Where("id = ?", job.ID).Pluck("cancel_requested", &cancelRequested).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if len(cancelRequested) == 0 {
+		return false, portrepository.ErrNotFound
+	}
+
+	return cancelRequested[0], nil
+}
+
+func (j *jobRepository) Finish(ctx context.Context, job *model.JobModel) error {
+	return j.DB.WithContext(ctx).Model(job).
+		Select("status", "result", "error", "progress", "total", "artifact_path", "finished_at").
+		Updates(job).Error
+}
+
+func (j *jobRepository) Requeue(ctx context.Context, job *model.JobModel) error {
+	return j.DB.WithContext(ctx).Model(job).
+		Where("status = ?", model.JobStatusRunning).
+		Updates(map[string]interface{}{"status": model.JobStatusQueued, "started_at": nil, "attempts": gorm.Expr("attempts - 1")}).Error
+}
+
+// RequestCancel cancels a queued job right away and flags a running one, the
+// worker notices the flag on its next heartbeat.
+func (j *jobRepository) RequestCancel(ctx context.Context, job *model.JobModel) error {
+	return j.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.JobModel{}).
+			Where("id = ? AND status = ?", job.ID, model.JobStatusQueued).
+			Updates(map[string]interface{}{"status": model.JobStatusCancelled, "cancel_requested": true, "finished_at": time.Now()}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.JobModel{}).
+			Where("id = ? AND status = ?", job.ID, model.JobStatusRunning).
+			Update("cancel_requested", true).Error; err != nil {
+			return err
+		}
+
+		return tx.First(job, "id = ?", job.ID).Error
+	})
+}
@@ -0,0 +1,54 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"codetest/internal/model"
+)
+
+func TestJobRepository_SQLite(t *testing.T) {
+	ctx := context.Background()
+	repo := NewJobRepository(newTestDB(t))
+
+	job := &model.JobModel{Type: model.JobTypeUserExport, Status: model.JobStatusQueued, Payload: model.JSON(`{"search":"doe"}`)}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claimed, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claimed == nil || claimed.ID != job.ID || claimed.Status != model.JobStatusRunning || claimed.Attempts != 1 {
+		t.Fatalf("expected the queued job to be claimed, got %+v", claimed)
+	}
+	if string(claimed.Payload) != `{"search":"doe"}` {
+		t.Errorf("expected the payload to round trip, got %s", claimed.Payload)
+	}
+
+	if next, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute)); err != nil || next != nil {
+		t.Fatalf("expected nothing left to claim, got %+v, %v", next, err)
+	}
+
+	if err := repo.Requeue(ctx, claimed); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claimed, err = repo.ClaimNext(ctx, time.Now().Add(-time.Minute)); err != nil || claimed == nil || claimed.Attempts != 1 {
+		t.Fatalf("expected the requeued job to be claimed on its first attempt again, got %+v, %v", claimed, err)
+	}
+
+	if err := repo.RequestCancel(ctx, claimed); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claimed.Progress = 5
+	cancelRequested, err := repo.Heartbeat(ctx, claimed)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cancelRequested {
+		t.Error("expected the heartbeat to report the cancellation")
+	}
+}
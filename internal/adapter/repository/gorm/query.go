@@ -0,0 +1,76 @@
+package gorm
+
+import (
+	"strings"
+
+	"codetest/internal/query"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper escapes patterns for LIKE ... ESCAPE '!'. A backslash would
+// itself need escaping in MySQL string literals.
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// ilike returns a case insensitive LIKE condition on column. Outside
+// Postgres it lowercases both sides, which SQLite only does for ASCII.
+func ilike(db *gorm.DB, column string, pattern string) string {
+	if isPostgres(db) {
+		return column + ` ILIKE ` + pattern + ` ESCAPE '!'`
+	}
+	return `LOWER(` + column + `) LIKE LOWER(` + pattern + `) ESCAPE '!'`
+}
+
+// applyFilters translates the parsed conditions into WHERE clauses. Columns
+// come from the query.Schema whitelist, values are always bound parameters.
+func applyFilters(db *gorm.DB, q *query.Query) *gorm.DB {
+	if q == nil {
+		return db
+	}
+
+	for _, cond := range q.Conditions {
+		switch cond.Operator {
+		case query.OpEq:
+			db = db.Where(cond.Column+" = ?", cond.Value)
+		case query.OpNe:
+			db = db.Where(cond.Column+" <> ?", cond.Value)
+		case query.OpLike:
+			db = db.Where(cond.Column+` LIKE ? ESCAPE '!'`, "%"+likeEscaper.Replace(cond.Value.(string))+"%")
+		case query.OpIlike:
+			db = db.Where(ilike(db, cond.Column, "?"), "%"+likeEscaper.Replace(cond.Value.(string))+"%")
+		case query.OpGt:
+			db = db.Where(cond.Column+" > ?", cond.Value)
+		case query.OpGte:
+			db = db.Where(cond.Column+" >= ?", cond.Value)
+		case query.OpLt:
+			db = db.Where(cond.Column+" < ?", cond.Value)
+		case query.OpLte:
+			db = db.Where(cond.Column+" <= ?", cond.Value)
+		case query.OpIn:
+			db = db.Where(cond.Column+" IN ?", cond.Value)
+		}
+	}
+
+	return db
+}
+
+// applySorts orders by the requested fields, falling back to defaultOrder.
+func applySorts(db *gorm.DB, q *query.Query, defaultOrder string) *gorm.DB {
+	if q == nil || len(q.Sorts) == 0 {
+		return db.Order(defaultOrder)
+	}
+
+	for _, s := range q.Sorts {
+		if s.Desc {
+			db = db.Order(s.Column + " DESC")
+		} else {
+			db = db.Order(s.Column + " ASC")
+		}
+	}
+
+	return db
+}
@@ -0,0 +1,113 @@
+package gorm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+
+	"gorm.io/gorm"
+)
+
+// userLogRecord is the user_logs row of a model.UserLogModel, Data and
+// TraceContext are stored as JSON (jsonb on Postgres).
+type userLogRecord struct {
+	ID           uint64
+	UserID       string
+	Event        model.UserLogEvent
+	Data         model.JSON
+	RequestID    string
+	TraceContext model.JSON
+	CreatedAt    time.Time
+}
+
+func (userLogRecord) TableName() string {
+	return "user_logs"
+}
+
+type userLogRepository struct {
+	DB *gorm.DB
+}
+
+func NewUserLogRepository(db *gorm.DB) portrepository.UserLogRepository {
+	return &userLogRepository{
+		DB: db,
+	}
+}
+
+func (u *userLogRepository) Create(ctx context.Context, userLog *model.UserLogModel) error {
+	record := &userLogRecord{
+		UserID:    userLog.UserID,
+		Event:     userLog.Event,
+		RequestID: userLog.RequestID,
+		CreatedAt: userLog.CreatedAt,
+	}
+
+	if userLog.Data != nil {
+		data, err := json.Marshal(userLog.Data)
+		if err != nil {
+			return err
+		}
+		record.Data = data
+	}
+
+	if len(userLog.TraceContext) > 0 {
+		traceContext, err := json.Marshal(userLog.TraceContext)
+		if err != nil {
+			return err
+		}
+		record.TraceContext = traceContext
+	}
+
+	return u.DB.WithContext(ctx).Create(record).Error
+}
+
+// Find filters, sorts and paginates like the Mongo repository, newest first
+// unless a sort is requested.
+func (u *userLogRepository) Find(ctx context.Context, request *dto.QueryUserLogRequest) ([]*model.UserLogModel, int64, error) {
+	var (
+		records []*userLogRecord
+		total   int64
+	)
+
+	query := applyFilters(u.DB.WithContext(ctx).Model(&userLogRecord{}), request.Query)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := applySorts(query, request.Query, "created_at DESC, id DESC").
+		Limit(request.PageSize).
+		Offset((request.Page - 1) * request.PageSize).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	userLogs := make([]*model.UserLogModel, 0, len(records))
+	for _, record := range records {
+		userLog := &model.UserLogModel{
+			UserID:    record.UserID,
+			Event:     record.Event,
+			RequestID: record.RequestID,
+			CreatedAt: record.CreatedAt,
+		}
+
+		if len(record.Data) > 0 {
+			if err := json.Unmarshal(record.Data, &userLog.Data); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		if len(record.TraceContext) > 0 {
+			if err := json.Unmarshal(record.TraceContext, &userLog.TraceContext); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		userLogs = append(userLogs, userLog)
+	}
+
+	return userLogs, total, nil
+}
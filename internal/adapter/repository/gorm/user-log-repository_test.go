@@ -0,0 +1,62 @@
+package gorm
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	"codetest/internal/query"
+)
+
+func TestUserLogRepository_SQLite(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserLogRepository(newTestDB(t))
+
+	start := time.Now().Add(-time.Hour)
+	for i, event := range []model.UserLogEvent{model.UserLogEventCreate, model.UserLogEventUpdate, model.UserLogEventDelete} {
+		err := repo.Create(ctx, &model.UserLogModel{
+			UserID:       "user-1",
+			Event:        event,
+			Data:         map[string]interface{}{"email": "john@doe.com"},
+			CreatedAt:    start.Add(time.Duration(i) * time.Minute),
+			RequestID:    "req-1",
+			TraceContext: map[string]string{"traceparent": "00-abc-def-01"},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	t.Run("newest first by default", func(t *testing.T) {
+		userLogs, total, err := repo.Find(ctx, &dto.QueryUserLogRequest{Page: 1, PageSize: 2})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 3 || len(userLogs) != 2 || userLogs[0].Event != model.UserLogEventDelete {
+			t.Fatalf("expected the 2 newest of 3 logs, got %d %+v", total, userLogs)
+		}
+
+		data, ok := userLogs[0].Data.(map[string]interface{})
+		if !ok || data["email"] != "john@doe.com" || userLogs[0].TraceContext["traceparent"] != "00-abc-def-01" {
+			t.Errorf("expected data and trace context to round trip, got %+v", userLogs[0])
+		}
+	})
+
+	t.Run("filters apply", func(t *testing.T) {
+		q, err := query.Parse(dto.UserLogQuerySchema, url.Values{"filter[event][in]": {"user:created,user:deleted"}, "sort": {"created_at"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		userLogs, total, err := repo.Find(ctx, &dto.QueryUserLogRequest{Page: 1, PageSize: 10, Query: q})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 2 || userLogs[0].Event != model.UserLogEventCreate {
+			t.Errorf("expected created then deleted, got %d %+v", total, userLogs)
+		}
+	})
+}
@@ -2,14 +2,30 @@ package gorm
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"codetest/internal/adapter/api/dto"
 	"codetest/internal/model"
 	portrepository "codetest/internal/port/repository"
+	"codetest/internal/requestctx"
 
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
+// searchSimilarityThreshold is the pg_trgm word similarity above which a
+// user is considered a match, low enough to tolerate typos in short names.
+const searchSimilarityThreshold = "0.3"
+
+// searchStreamTimeout bounds a streamed search on Postgres, which holds a
+// transaction open for as long as the reader takes to consume the rows.
+const searchStreamTimeout = 10 * time.Minute
+
+// streamBatchSize is the number of users Stream reads per query on SQLite,
+// where a cursor would hold the only connection for the whole walk.
+var streamBatchSize = 500
+
 type userRepository struct {
 	DB *gorm.DB
 }
@@ -21,7 +37,30 @@ func NewUserRepository(db *gorm.DB) portrepository.UserRepository {
 }
 
 func (u *userRepository) Create(ctx context.Context, user *model.UserModel) error {
-	return u.DB.WithContext(ctx).Create(user).Error
+	return translateError(u.DB.WithContext(ctx).Create(user).Error)
+}
+
+// CreateMany inserts users in a single transaction, either all of them are
+// created or none.
+func (u *userRepository) CreateMany(ctx context.Context, users []*model.UserModel) error {
+	return translateError(u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(users, len(users)).Error
+	}))
+}
+
+// ExistingEmails returns which of emails, given in lower case, belong to an
+// active user whatever the case they were registered with.
+func (u *userRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	var existing []string
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	if err := u.DB.WithContext(ctx).Model(&model.UserModel{}).Where("LOWER(email) IN ?", emails).Pluck("LOWER(email)", &existing).Error; err != nil {
+		return nil, err
+	}
+
+	return existing, nil
 }
 
 func (u *userRepository) Find(ctx context.Context, request *dto.QueryUserRequest) ([]*model.UserModel, int64, error) {
@@ -30,40 +69,293 @@ func (u *userRepository) Find(ctx context.Context, request *dto.QueryUserRequest
 		total int64
 	)
 
-	query := u.DB.WithContext(ctx).Model(&model.UserModel{})
+	err := u.withSearch(ctx, request, func(db *gorm.DB) error {
+		query, defaultOrder := u.filter(db, request)
+
+		if err := query.Count(&total).Error; err != nil {
+			return err
+		}
+
+		return applySorts(u.selectSearchRank(query, request), request.Query, defaultOrder).
+			Limit(request.PageSize).
+			Offset((request.Page - 1) * request.PageSize).
+			Find(&users).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// Stream walks every user matching request, ignoring pagination, through a
+// database cursor instead of loading them into memory. SQLite is read in
+// batches instead, releasing its single connection between them.
+func (u *userRepository) Stream(ctx context.Context, request *dto.QueryUserRequest, fn func(user *model.UserModel) error) error {
+	if u.DB.Dialector.Name() == "sqlite" {
+		return u.streamInBatches(ctx, request, fn)
+	}
+
+	if request.Search != "" && isPostgres(u.DB) {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, searchStreamTimeout)
+		defer cancel()
+	}
+
+	return u.withSearch(ctx, request, func(db *gorm.DB) error {
+		query, defaultOrder := u.filter(db, request)
+
+		rows, err := applySorts(u.selectSearchRank(query, request), request.Query, defaultOrder).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var user model.UserModel
+			if err := db.ScanRows(rows, &user); err != nil {
+				return err
+			}
+
+			if err := fn(&user); err != nil {
+				return err
+			}
+		}
+
+		return rows.Err()
+	})
+}
+
+// streamInBatches pages through the users matching request, ordered by ID
+// after the requested sort so pages never overlap.
+func (u *userRepository) streamInBatches(ctx context.Context, request *dto.QueryUserRequest, fn func(user *model.UserModel) error) error {
+	for offset := 0; ; offset += streamBatchSize {
+		query, defaultOrder := u.filter(u.DB.WithContext(ctx), request)
+
+		var users []*model.UserModel
+		if err := applySorts(u.selectSearchRank(query, request), request.Query, defaultOrder).
+			Order("id").
+			Limit(streamBatchSize).
+			Offset(offset).
+			Find(&users).Error; err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < streamBatchSize {
+			return nil
+		}
+	}
+}
+
+// withSearch runs fn on a session prepared for request. The threshold used by
+// the <% operator is a session setting, so Postgres searches are scoped to a
+// transaction to keep pooled connections unaffected.
+func (u *userRepository) withSearch(ctx context.Context, request *dto.QueryUserRequest, fn func(db *gorm.DB) error) error {
+	if request.Search == "" || !isPostgres(u.DB) {
+		return fn(u.DB.WithContext(ctx))
+	}
+
+	return u.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT set_config('pg_trgm.word_similarity_threshold', ?, true)", searchSimilarityThreshold).Error; err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
+
+// filter applies the trash, search and filter[...] conditions of request and
+// returns the ordering to use when no sort is requested.
+func (u *userRepository) filter(db *gorm.DB, request *dto.QueryUserRequest) (*gorm.DB, string) {
+	query := db.Model(&model.UserModel{})
+	defaultOrder := "created_at DESC"
+
+	switch request.Deleted {
+	case dto.DeletedInclude:
+		query = query.Unscoped()
+	case dto.DeletedOnly:
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
 
 	if request.Search != "" {
-		query = query.Where("name LIKE ?", "%"+request.Search+"%")
+		args := map[string]interface{}{
+			"term":    request.Search,
+			"pattern": "%" + likeEscaper.Replace(request.Search) + "%",
+		}
+
+		if isPostgres(db) {
+			// ILIKE and <% are both served by the gin_trgm_ops indexes on name and email.
+			query = query.Where(`(name ILIKE @pattern ESCAPE '!' OR email ILIKE @pattern ESCAPE '!' OR @term <% name OR @term <% email)`, args)
+		} else {
+			query = query.Where(`(`+ilike(db, "name", "@pattern")+` OR `+ilike(db, "email", "@pattern")+`)`, args)
+		}
+		defaultOrder = "search_rank DESC, created_at DESC"
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	return applyFilters(query, request.Query), defaultOrder
+}
+
+func (u *userRepository) selectSearchRank(query *gorm.DB, request *dto.QueryUserRequest) *gorm.DB {
+	if request.Search == "" {
+		return query
 	}
 
-	if err := query.
-		Limit(request.PageSize).
-		Offset((request.Page - 1) * request.PageSize).
-		Order("created_at DESC").
-		Find(&users).Error; err != nil {
-		return nil, 0, err
+	if isPostgres(query) {
+		return query.Select(`users.*,
+		GREATEST(word_similarity(@term, name), word_similarity(@term, email)) AS search_rank,
+		CASE WHEN word_similarity(@term, email) > word_similarity(@term, name) THEN 'email' ELSE 'name' END AS search_field`,
+			map[string]interface{}{"term": request.Search})
 	}
 
-	return users, total, nil
+	// Without pg_trgm the rank only tells exact matches from partial ones.
+	return query.Select(`users.*,
+		CASE WHEN LOWER(name) = LOWER(@term) OR LOWER(email) = LOWER(@term) THEN 1.0 ELSE 0.5 END AS search_rank,
+		CASE WHEN `+ilike(query, "name", "@pattern")+` THEN 'name' ELSE 'email' END AS search_field`,
+		map[string]interface{}{
+			"term":    request.Search,
+			"pattern": "%" + likeEscaper.Replace(request.Search) + "%",
+		})
 }
 
+// GetOneBy reads from a replica unless ctx asks for the primary with
+// requestctx.WithPrimaryRead.
 func (u *userRepository) GetOneBy(ctx context.Context, column string, value string) (*model.UserModel, error) {
+	db := u.DB.WithContext(ctx)
+	if requestctx.PrimaryRead(ctx) {
+		db = db.Clauses(dbresolver.Write)
+	}
+
 	var user model.UserModel
-	if err := u.DB.WithContext(ctx).Where(column+" = ?", value).First(&user).Error; err != nil {
-		return nil, err
+	if err := db.Where(column+" = ?", value).First(&user).Error; err != nil {
+		return nil, translateError(err)
 	}
 
 	return &user, nil
 }
 
+// Update replaces name and email (and password when set) only if Version
+// still matches the stored row, and bumps the version on success.
 func (u *userRepository) Update(ctx context.Context, user *model.UserModel) error {
-	return u.DB.WithContext(ctx).Where("id = ?", user.ID).Updates(user).Error
+	expected := user.Version
+	user.Version = expected + 1
+
+	columns := []string{"name", "email", "version"}
+	if user.Password != "" {
+		columns = append(columns, "password")
+	}
+
+	result := u.DB.WithContext(ctx).Model(user).Select(columns).Where("version = ?", expected).Updates(user)
+	if result.Error != nil {
+		user.Version = expected
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		user.Version = expected
+
+		// A lagging replica would report a conflict as not found.
+		var count int64
+		if err := u.DB.WithContext(ctx).Clauses(dbresolver.Write).Model(&model.UserModel{}).Where("id = ?", user.ID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return portrepository.ErrNotFound
+		}
+		return portrepository.ErrVersionConflict
+	}
+
+	return nil
+}
+
+// UpdateColumnsBy sets values on the user matching column and value without
+// a version check, bumping the version so concurrent editors notice.
+func (u *userRepository) UpdateColumnsBy(ctx context.Context, column string, value string, values map[string]interface{}) error {
+	updates := make(map[string]interface{}, len(values)+1)
+	for key, val := range values {
+		updates[key] = val
+	}
+	updates["version"] = gorm.Expr("version + 1")
+
+	result := u.DB.WithContext(ctx).Model(&model.UserModel{}).Where(column+" = ?", value).Updates(updates)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return portrepository.ErrNotFound
+	}
+
+	return nil
 }
 
+// DeleteOneBy soft deletes an active user, ErrNotFound when none matches.
 func (u *userRepository) DeleteOneBy(ctx context.Context, column string, value string) error {
-	return u.DB.WithContext(ctx).Where(column+" = ?", value).Delete(&model.UserModel{}).Error
+	result := u.DB.WithContext(ctx).Where(column+" = ?", value).Delete(&model.UserModel{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return portrepository.ErrNotFound
+	}
+
+	return nil
+}
+
+// RestoreOneBy clears deleted_at on a soft deleted user. It fails with
+// ErrAlreadyExists when the email was taken again in the meantime.
+func (u *userRepository) RestoreOneBy(ctx context.Context, column string, value string) error {
+	result := u.DB.WithContext(ctx).Unscoped().Model(&model.UserModel{}).
+		Where(column+" = ? AND deleted_at IS NOT NULL", value).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return portrepository.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeOneBy permanently removes a user, whether soft deleted or not.
+func (u *userRepository) PurgeOneBy(ctx context.Context, column string, value string) error {
+	result := u.DB.WithContext(ctx).Unscoped().Where(column+" = ?", value).Delete(&model.UserModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return portrepository.ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeletedBefore permanently removes users soft deleted before before.
+func (u *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := u.DB.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Delete(&model.UserModel{})
+
+	return result.RowsAffected, result.Error
+}
+
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return portrepository.ErrAlreadyExists
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return portrepository.ErrNotFound
+	default:
+		return err
+	}
 }
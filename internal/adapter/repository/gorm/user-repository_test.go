@@ -0,0 +1,202 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"codetest/database/migrations"
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	"codetest/internal/query"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/pressly/goose/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB returns an in-memory SQLite database with the SQLite migrations
+// applied.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	fsys, err := migrations.For("sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectSQLite3, sqlDB, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := provider.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestUserRepository_SQLite(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository(newTestDB(t))
+
+	john := &model.UserModel{Name: "John Doe", Email: "john@doe.com", Password: "hash", Role: model.UserRoleUser}
+	if err := repo.Create(ctx, john); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if john.ID == uuid.Nil {
+		t.Fatal("expected the ID to be generated")
+	}
+
+	if err := repo.CreateMany(ctx, []*model.UserModel{
+		{Name: "Jane Roe", Email: "Jane@Roe.com", Password: "hash", Role: model.UserRoleAdmin},
+		{Name: "100% Real", Email: "real@example.com", Password: "hash", Role: model.UserRoleUser},
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("duplicate email is rejected", func(t *testing.T) {
+		err := repo.Create(ctx, &model.UserModel{Name: "Other", Email: "john@doe.com", Password: "hash", Role: model.UserRoleUser})
+		if !errors.Is(err, portrepository.ErrAlreadyExists) {
+			t.Fatalf("expected ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("user is read back by ID", func(t *testing.T) {
+		got, err := repo.GetOneBy(ctx, "id", john.ID.String())
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if got.Email != john.Email || got.Version != 1 || got.CreatedAt.IsZero() {
+			t.Errorf("unexpected user %+v", got)
+		}
+	})
+
+	t.Run("existing emails are matched case insensitively", func(t *testing.T) {
+		existing, err := repo.ExistingEmails(ctx, []string{"jane@roe.com", "nobody@roe.com"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(existing) != 1 || existing[0] != "jane@roe.com" {
+			t.Errorf("expected jane@roe.com only, got %v", existing)
+		}
+	})
+
+	t.Run("missing user is not found", func(t *testing.T) {
+		if _, err := repo.GetOneBy(ctx, "id", uuid.NewString()); !errors.Is(err, portrepository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+
+		if err := repo.DeleteOneBy(ctx, "id", uuid.NewString()); !errors.Is(err, portrepository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("search matches names and emails case insensitively", func(t *testing.T) {
+		users, total, err := repo.Find(ctx, &dto.QueryUserRequest{Search: "ROE", Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 1 || len(users) != 1 || users[0].Email != "Jane@Roe.com" || users[0].SearchField != "name" {
+			t.Errorf("expected Jane matched on her name, got %d users %+v", total, users)
+		}
+	})
+
+	t.Run("search escapes wildcards", func(t *testing.T) {
+		_, total, err := repo.Find(ctx, &dto.QueryUserRequest{Search: "0%", Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 1 {
+			t.Errorf("expected only the literal match, got %d", total)
+		}
+	})
+
+	t.Run("filters and sorts apply", func(t *testing.T) {
+		q, err := query.Parse(dto.UserQuerySchema, url.Values{
+			"filter[email][ilike]": {"DOE"},
+			"sort":                 {"-name"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		users, total, err := repo.Find(ctx, &dto.QueryUserRequest{Query: q, Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 1 || users[0].ID != john.ID {
+			t.Errorf("expected John only, got %d users %+v", total, users)
+		}
+	})
+
+	t.Run("stream reads sqlite in batches", func(t *testing.T) {
+		batchSize := streamBatchSize
+		streamBatchSize = 2
+		t.Cleanup(func() { streamBatchSize = batchSize })
+
+		count := 0
+		seen := make(map[uuid.UUID]bool)
+		err := repo.Stream(ctx, &dto.QueryUserRequest{}, func(user *model.UserModel) error {
+			count++
+			seen[user.ID] = true
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if count != 3 || len(seen) != 3 || !seen[john.ID] {
+			t.Errorf("expected every user once, got %d rows for %v", count, seen)
+		}
+	})
+
+	t.Run("stale version is a conflict", func(t *testing.T) {
+		stale := *john
+		john.Name = "John Updated"
+		if err := repo.Update(ctx, john); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		stale.Name = "John Stale"
+		if err := repo.Update(ctx, &stale); !errors.Is(err, portrepository.ErrVersionConflict) {
+			t.Fatalf("expected ErrVersionConflict, got %v", err)
+		}
+	})
+
+	t.Run("soft deleted email can be registered again", func(t *testing.T) {
+		if err := repo.DeleteOneBy(ctx, "id", john.ID.String()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if err := repo.DeleteOneBy(ctx, "id", john.ID.String()); !errors.Is(err, portrepository.ErrNotFound) {
+			t.Fatalf("expected deleting twice to be ErrNotFound, got %v", err)
+		}
+
+		if err := repo.Create(ctx, &model.UserModel{Name: "New John", Email: "john@doe.com", Password: "hash", Role: model.UserRoleUser}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if err := repo.RestoreOneBy(ctx, "id", john.ID.String()); !errors.Is(err, portrepository.ErrAlreadyExists) {
+			t.Fatalf("expected ErrAlreadyExists, got %v", err)
+		}
+	})
+}
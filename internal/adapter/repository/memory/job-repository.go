@@ -0,0 +1,187 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+
+	"github.com/google/uuid"
+)
+
+// jobRepository keeps jobs in a map. A single mutex serializes ClaimNext, so
+// concurrent workers never claim the same job.
+type jobRepository struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]*model.JobModel
+}
+
+func NewJobRepository() portrepository.JobRepository {
+	return &jobRepository{
+		jobs: make(map[uuid.UUID]*model.JobModel),
+	}
+}
+
+func (j *jobRepository) Create(ctx context.Context, job *model.JobModel) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if _, ok := j.jobs[job.ID]; ok {
+		return portrepository.ErrAlreadyExists
+	}
+	if job.Status == "" {
+		job.Status = model.JobStatusQueued
+	}
+	now := time.Now()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	j.jobs[job.ID] = cloneJob(job)
+	return nil
+}
+
+func (j *jobRepository) GetOneBy(ctx context.Context, column string, value string) (*model.JobModel, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, job := range j.jobs {
+		if jobField(job, column) == value {
+			return cloneJob(job), nil
+		}
+	}
+
+	return nil, portrepository.ErrNotFound
+}
+
+// ClaimNext marks the oldest queued or stale running job as running.
+func (j *jobRepository) ClaimNext(ctx context.Context, staleBefore time.Time) (*model.JobModel, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var next *model.JobModel
+	for _, job := range j.jobs {
+		claimable := job.Status == model.JobStatusQueued ||
+			(job.Status == model.JobStatusRunning && job.UpdatedAt.Before(staleBefore))
+		if claimable && (next == nil || job.CreatedAt.Before(next.CreatedAt)) {
+			next = job
+		}
+	}
+
+	if next == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	next.Status = model.JobStatusRunning
+	next.Attempts++
+	next.StartedAt = &now
+	next.UpdatedAt = now
+
+	return cloneJob(next), nil
+}
+
+func (j *jobRepository) Heartbeat(ctx context.Context, job *model.JobModel) (bool, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stored, ok := j.jobs[job.ID]
+	if !ok {
+		return false, portrepository.ErrNotFound
+	}
+
+	stored.Progress = job.Progress
+	stored.Total = job.Total
+	stored.UpdatedAt = time.Now()
+
+	return stored.CancelRequested, nil
+}
+
+func (j *jobRepository) Finish(ctx context.Context, job *model.JobModel) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stored, ok := j.jobs[job.ID]
+	if !ok {
+		return nil
+	}
+
+	stored.Status = job.Status
+	stored.Result = slices.Clone(job.Result)
+	stored.Error = job.Error
+	stored.Progress = job.Progress
+	stored.Total = job.Total
+	stored.ArtifactPath = job.ArtifactPath
+	stored.FinishedAt = job.FinishedAt
+	stored.UpdatedAt = time.Now()
+
+	return nil
+}
+
+func (j *jobRepository) Requeue(ctx context.Context, job *model.JobModel) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if stored, ok := j.jobs[job.ID]; ok && stored.Status == model.JobStatusRunning {
+		stored.Status = model.JobStatusQueued
+		stored.Attempts--
+		stored.StartedAt = nil
+		stored.UpdatedAt = time.Now()
+	}
+
+	return nil
+}
+
+// RequestCancel cancels a queued job right away and flags a running one, the
+// worker notices the flag on its next heartbeat.
+func (j *jobRepository) RequestCancel(ctx context.Context, job *model.JobModel) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	stored, ok := j.jobs[job.ID]
+	if !ok {
+		return portrepository.ErrNotFound
+	}
+
+	now := time.Now()
+	switch stored.Status {
+	case model.JobStatusQueued:
+		stored.Status = model.JobStatusCancelled
+		stored.CancelRequested = true
+		stored.FinishedAt = &now
+		stored.UpdatedAt = now
+	case model.JobStatusRunning:
+		stored.CancelRequested = true
+		stored.UpdatedAt = now
+	}
+
+	*job = *cloneJob(stored)
+	return nil
+}
+
+func jobField(job *model.JobModel, column string) string {
+	switch column {
+	case "id":
+		return job.ID.String()
+	case "type":
+		return job.Type.String()
+	case "status":
+		return job.Status.String()
+	case "created_by":
+		return job.CreatedBy
+	}
+
+	return ""
+}
+
+func cloneJob(job *model.JobModel) *model.JobModel {
+	clone := *job
+	clone.Payload = slices.Clone(job.Payload)
+	clone.Result = slices.Clone(job.Result)
+	return &clone
+}
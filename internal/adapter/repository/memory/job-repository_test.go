@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"codetest/internal/model"
+)
+
+func TestJobRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewJobRepository()
+
+	job := &model.JobModel{Type: model.JobTypeUserExport, Payload: model.JSON(`{"format":"csv"}`)}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if job.Status != model.JobStatusQueued {
+		t.Fatalf("expected a queued job, got %q", job.Status)
+	}
+
+	claimed, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claimed == nil || claimed.ID != job.ID || claimed.Status != model.JobStatusRunning || claimed.Attempts != 1 {
+		t.Fatalf("expected the job to be claimed, got %+v", claimed)
+	}
+
+	if next, err := repo.ClaimNext(ctx, time.Now().Add(-time.Minute)); err != nil || next != nil {
+		t.Fatalf("expected nothing left to claim, got %+v, %v", next, err)
+	}
+
+	// A requeued job was interrupted, its claim does not count as an attempt.
+	if err := repo.Requeue(ctx, claimed); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if claimed, err = repo.ClaimNext(ctx, time.Now().Add(-time.Minute)); err != nil || claimed == nil || claimed.Attempts != 1 {
+		t.Fatalf("expected the requeued job to be claimed on its first attempt again, got %+v, %v", claimed, err)
+	}
+
+	if err := repo.RequestCancel(ctx, job); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	claimed.Progress = 5
+	cancelRequested, err := repo.Heartbeat(ctx, claimed)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !cancelRequested {
+		t.Error("expected the heartbeat to report the cancellation")
+	}
+
+	got, err := repo.GetOneBy(ctx, "id", job.ID.String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Progress != 5 || string(got.Payload) != `{"format":"csv"}` {
+		t.Errorf("unexpected job %+v", got)
+	}
+}
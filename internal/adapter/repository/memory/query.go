@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"codetest/internal/query"
+)
+
+// fieldFunc returns the value stored under column, a string, a float64, a
+// time.Time or nil for NULL.
+type fieldFunc[T any] func(item T, column string) interface{}
+
+// applyFilters keeps the items matching every condition, mirroring the SQL
+// adapters: like is case sensitive, ilike is not, and NULL never matches.
+func applyFilters[T any](items []T, field fieldFunc[T], q *query.Query) []T {
+	if q == nil || len(q.Conditions) == 0 {
+		return items
+	}
+
+	return slices.DeleteFunc(items, func(item T) bool {
+		for _, cond := range q.Conditions {
+			if !matches(field(item, cond.Column), cond) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func matches(value interface{}, cond query.Condition) bool {
+	if value == nil {
+		return false
+	}
+
+	switch cond.Operator {
+	case query.OpLike:
+		s, _ := value.(string)
+		return strings.Contains(s, cond.Value.(string))
+	case query.OpIlike:
+		s, _ := value.(string)
+		return strings.Contains(strings.ToLower(s), strings.ToLower(cond.Value.(string)))
+	case query.OpIn:
+		s, _ := value.(string)
+		return slices.Contains(cond.Value.([]string), s)
+	}
+
+	c := compare(value, cond.Value)
+	switch cond.Operator {
+	case query.OpEq:
+		return c == 0
+	case query.OpNe:
+		return c != 0
+	case query.OpGt:
+		return c > 0
+	case query.OpGte:
+		return c >= 0
+	case query.OpLt:
+		return c < 0
+	case query.OpLte:
+		return c <= 0
+	default:
+		return false
+	}
+}
+
+// applySorts orders items by the requested fields, falling back to
+// defaultSorts. NULLs come last in ascending order, as in Postgres.
+func applySorts[T any](items []T, field fieldFunc[T], q *query.Query, defaultSorts []query.Sort) {
+	sorts := defaultSorts
+	if q != nil && len(q.Sorts) > 0 {
+		sorts = q.Sorts
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		for _, s := range sorts {
+			c := compare(field(a, s.Column), field(b, s.Column))
+			if s.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// paginate returns the page of items, page starting at 1.
+func paginate[T any](items []T, page, pageSize int) []T {
+	start := (page - 1) * pageSize
+	if start < 0 || start >= len(items) {
+		return items[:0]
+	}
+
+	return items[start:min(start+pageSize, len(items))]
+}
+
+func compare(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	switch a := a.(type) {
+	case string:
+		b, _ := b.(string)
+		return strings.Compare(a, b)
+	case float64:
+		b, _ := b.(float64)
+		return cmp.Compare(a, b)
+	case time.Time:
+		b, _ := b.(time.Time)
+		return a.Compare(b)
+	default:
+		return 0
+	}
+}
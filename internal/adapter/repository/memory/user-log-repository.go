@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	"codetest/internal/query"
+)
+
+type userLogRepository struct {
+	mu       sync.RWMutex
+	userLogs []*model.UserLogModel
+}
+
+func NewUserLogRepository() portrepository.UserLogRepository {
+	return &userLogRepository{}
+}
+
+func (u *userLogRepository) Create(ctx context.Context, userLog *model.UserLogModel) error {
+	stored := *userLog
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.userLogs = append(u.userLogs, &stored)
+	return nil
+}
+
+// Find filters, sorts and paginates like the other repositories, newest
+// first unless a sort is requested.
+func (u *userLogRepository) Find(ctx context.Context, request *dto.QueryUserLogRequest) ([]*model.UserLogModel, int64, error) {
+	u.mu.RLock()
+	userLogs := make([]*model.UserLogModel, 0, len(u.userLogs))
+	// Newest first so equal created_at values keep insertion order reversed,
+	// like the id DESC tie breaker of the SQL repository.
+	for i := len(u.userLogs) - 1; i >= 0; i-- {
+		userLog := *u.userLogs[i]
+		userLogs = append(userLogs, &userLog)
+	}
+	u.mu.RUnlock()
+
+	userLogs = applyFilters(userLogs, userLogField, request.Query)
+	applySorts(userLogs, userLogField, request.Query, []query.Sort{{Column: "created_at", Desc: true}})
+
+	return paginate(userLogs, request.Page, request.PageSize), int64(len(userLogs)), nil
+}
+
+func userLogField(userLog *model.UserLogModel, column string) interface{} {
+	switch column {
+	case "user_id":
+		return userLog.UserID
+	case "event":
+		return userLog.Event.String()
+	case "request_id":
+		return userLog.RequestID
+	case "created_at":
+		return userLog.CreatedAt
+	}
+
+	return nil
+}
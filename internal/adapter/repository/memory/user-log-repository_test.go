@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	"codetest/internal/query"
+)
+
+func TestUserLogRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserLogRepository()
+
+	now := time.Now()
+	for i, event := range []model.UserLogEvent{model.UserLogEventCreate, model.UserLogEventUpdate, model.UserLogEventDelete} {
+		if err := repo.Create(ctx, &model.UserLogModel{
+			UserID:    "admin",
+			Event:     event,
+			Data:      map[string]interface{}{"n": i},
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	t.Run("newest first by default", func(t *testing.T) {
+		userLogs, total, err := repo.Find(ctx, &dto.QueryUserLogRequest{Page: 1, PageSize: 2})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 3 || len(userLogs) != 2 || userLogs[0].Event != model.UserLogEventDelete {
+			t.Errorf("expected the delete event first of 3, got %d logs %+v", total, userLogs)
+		}
+	})
+
+	t.Run("filters apply", func(t *testing.T) {
+		q, err := query.Parse(dto.UserLogQuerySchema, url.Values{"filter[event]": {"user:updated"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		userLogs, total, err := repo.Find(ctx, &dto.QueryUserLogRequest{Query: q, Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 1 || userLogs[0].Event != model.UserLogEventUpdate {
+			t.Errorf("expected the update event only, got %d logs %+v", total, userLogs)
+		}
+	})
+}
@@ -0,0 +1,389 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	"codetest/internal/query"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// userRepository keeps users in a map, with the soft delete, unique active
+// email and optimistic locking rules of the SQL repository.
+type userRepository struct {
+	mu    sync.RWMutex
+	users map[uuid.UUID]*model.UserModel
+}
+
+func NewUserRepository() portrepository.UserRepository {
+	return &userRepository{
+		users: make(map[uuid.UUID]*model.UserModel),
+	}
+}
+
+func (u *userRepository) Create(ctx context.Context, user *model.UserModel) error {
+	return u.CreateMany(ctx, []*model.UserModel{user})
+}
+
+// CreateMany inserts either all users or none.
+func (u *userRepository) CreateMany(ctx context.Context, users []*model.UserModel) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	emails := make(map[string]bool, len(users))
+	for _, user := range users {
+		if emails[user.Email] || u.activeEmail(user.Email, uuid.Nil) {
+			return portrepository.ErrAlreadyExists
+		}
+		emails[user.Email] = true
+	}
+
+	now := time.Now()
+	for _, user := range users {
+		if user.ID == uuid.Nil {
+			user.ID = uuid.New()
+		}
+		if user.Role == "" {
+			user.Role = model.UserRoleUser
+		}
+		if user.Version == 0 {
+			user.Version = 1
+		}
+		user.CreatedAt = now
+		user.UpdatedAt = now
+
+		stored := *user
+		u.users[user.ID] = &stored
+	}
+
+	return nil
+}
+
+// ExistingEmails returns which of emails, given in lower case, belong to an
+// active user whatever the case they were registered with.
+func (u *userRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	existing := []string{}
+	for _, email := range emails {
+		for _, user := range u.users {
+			if !user.DeletedAt.Valid && strings.EqualFold(user.Email, email) {
+				existing = append(existing, email)
+				break
+			}
+		}
+	}
+
+	return existing, nil
+}
+
+func (u *userRepository) Find(ctx context.Context, request *dto.QueryUserRequest) ([]*model.UserModel, int64, error) {
+	users := u.filter(request)
+	return paginate(users, request.Page, request.PageSize), int64(len(users)), nil
+}
+
+func (u *userRepository) Stream(ctx context.Context, request *dto.QueryUserRequest, fn func(user *model.UserModel) error) error {
+	for _, user := range u.filter(request) {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// filter returns copies of the users matching request in the requested
+// order. Searches match name and email case insensitively and rank exact
+// matches first, like the SQL repository without pg_trgm.
+func (u *userRepository) filter(request *dto.QueryUserRequest) []*model.UserModel {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	term := strings.ToLower(request.Search)
+	users := make([]*model.UserModel, 0, len(u.users))
+	for _, stored := range u.users {
+		switch {
+		case request.Deleted == dto.DeletedOnly && !stored.DeletedAt.Valid:
+			continue
+		case request.Deleted == "" && stored.DeletedAt.Valid:
+			continue
+		}
+
+		user := *stored
+		if term != "" {
+			name, email := strings.ToLower(user.Name), strings.ToLower(user.Email)
+			if !strings.Contains(name, term) && !strings.Contains(email, term) {
+				continue
+			}
+
+			user.SearchRank = 0.5
+			if name == term || email == term {
+				user.SearchRank = 1
+			}
+			user.SearchField = "email"
+			if strings.Contains(name, term) {
+				user.SearchField = "name"
+			}
+		}
+		users = append(users, &user)
+	}
+
+	// Map order is random, ties are broken by id so pages stay consistent.
+	slices.SortFunc(users, func(a, b *model.UserModel) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	users = applyFilters(users, userField, request.Query)
+
+	defaultSorts := []query.Sort{{Column: "created_at", Desc: true}}
+	if term != "" {
+		defaultSorts = append([]query.Sort{{Column: "search_rank", Desc: true}}, defaultSorts...)
+	}
+	applySorts(users, userField, request.Query, defaultSorts)
+
+	return users
+}
+
+func (u *userRepository) GetOneBy(ctx context.Context, column string, value string) (*model.UserModel, error) {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	user := u.find(column, value, false)
+	if user == nil {
+		return nil, portrepository.ErrNotFound
+	}
+
+	found := *user
+	return &found, nil
+}
+
+// Update replaces name and email (and password when set) only if Version
+// still matches the stored user, and bumps the version on success.
+func (u *userRepository) Update(ctx context.Context, user *model.UserModel) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stored, ok := u.users[user.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return portrepository.ErrNotFound
+	}
+	if stored.Version != user.Version {
+		return portrepository.ErrVersionConflict
+	}
+	if u.activeEmail(user.Email, user.ID) {
+		return portrepository.ErrAlreadyExists
+	}
+
+	user.Version++
+	user.UpdatedAt = time.Now()
+
+	stored.Name = user.Name
+	stored.Email = user.Email
+	if user.Password != "" {
+		stored.Password = user.Password
+	}
+	stored.Version = user.Version
+	stored.UpdatedAt = user.UpdatedAt
+
+	return nil
+}
+
+// UpdateColumnsBy sets values on the user matching column and value without
+// a version check, bumping the version so concurrent editors notice.
+func (u *userRepository) UpdateColumnsBy(ctx context.Context, column string, value string, values map[string]interface{}) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stored := u.find(column, value, false)
+	if stored == nil {
+		return portrepository.ErrNotFound
+	}
+
+	updated := *stored
+	for key, val := range values {
+		if err := setUserColumn(&updated, key, val); err != nil {
+			return err
+		}
+	}
+	if updated.Email != stored.Email && u.activeEmail(updated.Email, updated.ID) {
+		return portrepository.ErrAlreadyExists
+	}
+
+	updated.Version++
+	updated.UpdatedAt = time.Now()
+	*stored = updated
+
+	return nil
+}
+
+// DeleteOneBy soft deletes an active user, ErrNotFound when none matches.
+func (u *userRepository) DeleteOneBy(ctx context.Context, column string, value string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user := u.find(column, value, false)
+	if user == nil {
+		return portrepository.ErrNotFound
+	}
+
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+// RestoreOneBy clears DeletedAt on a soft deleted user. It fails with
+// ErrAlreadyExists when the email was taken again in the meantime.
+func (u *userRepository) RestoreOneBy(ctx context.Context, column string, value string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, user := range u.users {
+		if !user.DeletedAt.Valid || userField(user, column) != value {
+			continue
+		}
+
+		if u.activeEmail(user.Email, user.ID) {
+			return portrepository.ErrAlreadyExists
+		}
+		user.DeletedAt = gorm.DeletedAt{}
+		return nil
+	}
+
+	return portrepository.ErrNotFound
+}
+
+// PurgeOneBy permanently removes a user, whether soft deleted or not.
+func (u *userRepository) PurgeOneBy(ctx context.Context, column string, value string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	user := u.find(column, value, true)
+	if user == nil {
+		return portrepository.ErrNotFound
+	}
+
+	delete(u.users, user.ID)
+	return nil
+}
+
+// PurgeDeletedBefore permanently removes users soft deleted before before.
+func (u *userRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var purged int64
+	for id, user := range u.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) {
+			delete(u.users, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// find returns the stored user whose column equals value, callers hold the
+// lock.
+func (u *userRepository) find(column, value string, withDeleted bool) *model.UserModel {
+	for _, user := range u.users {
+		if (withDeleted || !user.DeletedAt.Valid) && userField(user, column) == value {
+			return user
+		}
+	}
+
+	return nil
+}
+
+// activeEmail reports whether a user other than except, not soft deleted,
+// has email. Callers hold the lock.
+func (u *userRepository) activeEmail(email string, except uuid.UUID) bool {
+	for _, user := range u.users {
+		if user.ID != except && !user.DeletedAt.Valid && user.Email == email {
+			return true
+		}
+	}
+
+	return false
+}
+
+func userField(user *model.UserModel, column string) interface{} {
+	switch column {
+	case "id":
+		return user.ID.String()
+	case "name":
+		return user.Name
+	case "email":
+		return user.Email
+	case "role":
+		return user.Role.String()
+	case "created_at":
+		return user.CreatedAt
+	case "updated_at":
+		return user.UpdatedAt
+	case "deleted_at":
+		if user.DeletedAt.Valid {
+			return user.DeletedAt.Time
+		}
+	case "disabled_at":
+		if user.DisabledAt != nil {
+			return *user.DisabledAt
+		}
+	case "search_rank":
+		return user.SearchRank
+	}
+
+	return nil
+}
+
+// setUserColumn applies one UpdateColumnsBy value, accepting the types the
+// SQL driver would.
+func setUserColumn(user *model.UserModel, column string, value interface{}) error {
+	switch column {
+	case "name":
+		if name, ok := value.(string); ok {
+			user.Name = name
+			return nil
+		}
+	case "email":
+		if email, ok := value.(string); ok {
+			user.Email = email
+			return nil
+		}
+	case "password":
+		if password, ok := value.(string); ok {
+			user.Password = password
+			return nil
+		}
+	case "role":
+		switch role := value.(type) {
+		case model.UserRole:
+			user.Role = role
+			return nil
+		case string:
+			user.Role = model.UserRole(role)
+			return nil
+		}
+	case "disabled_at":
+		switch disabledAt := value.(type) {
+		case nil:
+			user.DisabledAt = nil
+			return nil
+		case time.Time:
+			user.DisabledAt = &disabledAt
+			return nil
+		case *time.Time:
+			user.DisabledAt = disabledAt
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cannot set users.%s to %T", column, value)
+}
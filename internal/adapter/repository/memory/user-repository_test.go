@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	"codetest/internal/query"
+
+	"github.com/google/uuid"
+)
+
+func TestUserRepository(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository()
+
+	john := &model.UserModel{Name: "John Doe", Email: "john@doe.com", Password: "hash"}
+	if err := repo.Create(ctx, john); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if john.ID == uuid.Nil || john.Role != model.UserRoleUser {
+		t.Fatalf("expected the ID and role to be set, got %+v", john)
+	}
+
+	if err := repo.CreateMany(ctx, []*model.UserModel{
+		{Name: "Jane Roe", Email: "jane@roe.com", Password: "hash", Role: model.UserRoleAdmin},
+		{Name: "100% Real", Email: "real@example.com", Password: "hash"},
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("duplicate email rejects the whole batch", func(t *testing.T) {
+		err := repo.CreateMany(ctx, []*model.UserModel{
+			{Name: "Other", Email: "other@example.com", Password: "hash"},
+			{Name: "Other John", Email: "john@doe.com", Password: "hash"},
+		})
+		if !errors.Is(err, portrepository.ErrAlreadyExists) {
+			t.Fatalf("expected ErrAlreadyExists, got %v", err)
+		}
+
+		existing, err := repo.ExistingEmails(ctx, []string{"other@example.com", "john@doe.com"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(existing) != 1 || existing[0] != "john@doe.com" {
+			t.Errorf("expected only john@doe.com to exist, got %v", existing)
+		}
+	})
+
+	t.Run("returned users are copies", func(t *testing.T) {
+		got, err := repo.GetOneBy(ctx, "email", "john@doe.com")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		got.Name = "Changed"
+
+		again, _ := repo.GetOneBy(ctx, "id", john.ID.String())
+		if again.Name != "John Doe" || again.Version != 1 {
+			t.Errorf("expected the stored user untouched, got %+v", again)
+		}
+	})
+
+	t.Run("unknown user is not found", func(t *testing.T) {
+		if _, err := repo.GetOneBy(ctx, "id", uuid.NewString()); !errors.Is(err, portrepository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("search matches names and emails case insensitively", func(t *testing.T) {
+		users, total, err := repo.Find(ctx, &dto.QueryUserRequest{Search: "ROE", Page: 1, PageSize: 10})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 1 || len(users) != 1 || users[0].Email != "jane@roe.com" || users[0].SearchField != "name" {
+			t.Errorf("expected Jane matched on her name, got %d users %+v", total, users)
+		}
+	})
+
+	t.Run("filters, sorts and pagination apply", func(t *testing.T) {
+		q, err := query.Parse(dto.UserQuerySchema, url.Values{
+			"filter[name][ilike]": {"j"},
+			"sort":                {"name"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		users, total, err := repo.Find(ctx, &dto.QueryUserRequest{Query: q, Page: 2, PageSize: 1})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if total != 2 || len(users) != 1 || users[0].Name != "John Doe" {
+			t.Errorf("expected John alone on page 2 of 2 users, got %d users %+v", total, users)
+		}
+	})
+
+	t.Run("stale version is a conflict", func(t *testing.T) {
+		current, _ := repo.GetOneBy(ctx, "id", john.ID.String())
+		stale := *current
+
+		current.Name = "John Updated"
+		if err := repo.Update(ctx, current); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if current.Version != 2 {
+			t.Errorf("expected version 2, got %d", current.Version)
+		}
+
+		stale.Name = "John Stale"
+		if err := repo.Update(ctx, &stale); !errors.Is(err, portrepository.ErrVersionConflict) {
+			t.Fatalf("expected ErrVersionConflict, got %v", err)
+		}
+	})
+
+	t.Run("columns are updated by key", func(t *testing.T) {
+		if err := repo.UpdateColumnsBy(ctx, "id", john.ID.String(), map[string]interface{}{"role": model.UserRoleAdmin, "disabled_at": time.Now()}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		got, _ := repo.GetOneBy(ctx, "id", john.ID.String())
+		if got.Role != model.UserRoleAdmin || got.DisabledAt == nil {
+			t.Errorf("expected an admin disabled user, got %+v", got)
+		}
+
+		if err := repo.UpdateColumnsBy(ctx, "id", john.ID.String(), map[string]interface{}{"unknown": 1}); err == nil {
+			t.Error("expected an error for an unknown column")
+		}
+	})
+
+	t.Run("soft deleted email can be registered again", func(t *testing.T) {
+		if err := repo.DeleteOneBy(ctx, "id", john.ID.String()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := repo.DeleteOneBy(ctx, "id", john.ID.String()); !errors.Is(err, portrepository.ErrNotFound) {
+			t.Fatalf("expected deleting twice to be ErrNotFound, got %v", err)
+		}
+
+		_, total, _ := repo.Find(ctx, &dto.QueryUserRequest{Deleted: dto.DeletedOnly, Page: 1, PageSize: 10})
+		if total != 1 {
+			t.Errorf("expected one deleted user, got %d", total)
+		}
+
+		if err := repo.Create(ctx, &model.UserModel{Name: "New John", Email: "john@doe.com", Password: "hash"}); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if err := repo.RestoreOneBy(ctx, "id", john.ID.String()); !errors.Is(err, portrepository.ErrAlreadyExists) {
+			t.Fatalf("expected ErrAlreadyExists, got %v", err)
+		}
+	})
+
+	t.Run("deleted users are purged", func(t *testing.T) {
+		purged, err := repo.PurgeDeletedBefore(ctx, time.Now().Add(time.Second))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("expected 1 purged user, got %d", purged)
+		}
+
+		if err := repo.PurgeOneBy(ctx, "id", john.ID.String()); !errors.Is(err, portrepository.ErrNotFound) {
+			t.Fatalf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestUserRepository_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserRepository()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Every email is created twice, only one of each may win.
+			_ = repo.Create(ctx, &model.UserModel{Name: "User", Email: fmt.Sprintf("user%d@example.com", i%25), Password: "hash"})
+			_, _, _ = repo.Find(ctx, &dto.QueryUserRequest{Search: "user", Page: 1, PageSize: 10})
+		}(i)
+	}
+	wg.Wait()
+
+	_, total, err := repo.Find(ctx, &dto.QueryUserRequest{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if total != 25 {
+		t.Errorf("expected 25 users, got %d", total)
+	}
+}
@@ -0,0 +1,68 @@
+package mongo
+
+import (
+	"regexp"
+
+	"codetest/internal/query"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// buildFilter translates the parsed conditions into a Mongo filter document.
+// Fields come from the query.Schema whitelist and user supplied patterns are
+// always regex-quoted.
+func buildFilter(q *query.Query) bson.D {
+	if q == nil || len(q.Conditions) == 0 {
+		return bson.D{}
+	}
+
+	and := make(bson.A, 0, len(q.Conditions))
+	for _, cond := range q.Conditions {
+		var expr interface{}
+
+		switch cond.Operator {
+		case query.OpEq:
+			expr = cond.Value
+		case query.OpNe:
+			expr = bson.D{{Key: "$ne", Value: cond.Value}}
+		case query.OpLike:
+			expr = bson.D{{Key: "$regex", Value: regexp.QuoteMeta(cond.Value.(string))}}
+		case query.OpIlike:
+			expr = bson.D{{Key: "$regex", Value: regexp.QuoteMeta(cond.Value.(string))}, {Key: "$options", Value: "i"}}
+		case query.OpGt:
+			expr = bson.D{{Key: "$gt", Value: cond.Value}}
+		case query.OpGte:
+			expr = bson.D{{Key: "$gte", Value: cond.Value}}
+		case query.OpLt:
+			expr = bson.D{{Key: "$lt", Value: cond.Value}}
+		case query.OpLte:
+			expr = bson.D{{Key: "$lte", Value: cond.Value}}
+		case query.OpIn:
+			expr = bson.D{{Key: "$in", Value: cond.Value}}
+		default:
+			continue
+		}
+
+		and = append(and, bson.D{{Key: cond.Column, Value: expr}})
+	}
+
+	return bson.D{{Key: "$and", Value: and}}
+}
+
+// buildSort orders by the requested fields, falling back to newest first.
+func buildSort(q *query.Query) bson.D {
+	if q == nil || len(q.Sorts) == 0 {
+		return bson.D{{Key: "created_at", Value: -1}}
+	}
+
+	sort := make(bson.D, 0, len(q.Sorts))
+	for _, s := range q.Sorts {
+		direction := 1
+		if s.Desc {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: s.Column, Value: direction})
+	}
+
+	return sort
+}
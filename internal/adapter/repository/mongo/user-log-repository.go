@@ -2,13 +2,14 @@ package mongo
 
 import (
 	"codetest/internal/adapter/api/dto"
+	"codetest/internal/logger"
+	"codetest/internal/metrics"
 	"codetest/internal/model"
 	portrepository "codetest/internal/port/repository"
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
-	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
@@ -30,9 +31,12 @@ func NewUserLogRepository(db *mongo.Client, database, collection string) portrep
 
 func (u *userLogRepository) Create(ctx context.Context, userLog *model.UserLogModel) error {
 	coll := u.DB.Database(u.database).Collection(u.collection)
-	_, err := coll.InsertOne(ctx, userLog)
+	if _, err := coll.InsertOne(ctx, userLog); err != nil {
+		metrics.MongoInsertErrors.WithLabelValues(u.collection).Inc()
+		return err
+	}
 
-	return err
+	return nil
 }
 
 func (u *userLogRepository) Find(ctx context.Context, request *dto.QueryUserLogRequest) ([]*model.UserLogModel, int64, error) {
@@ -40,16 +44,18 @@ func (u *userLogRepository) Find(ctx context.Context, request *dto.QueryUserLogR
 
 	coll := u.DB.Database(u.database).Collection(u.collection)
 
-	total, err := coll.CountDocuments(ctx, bson.M{})
+	filter := buildFilter(request.Query)
+
+	total, err := coll.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count documents: %w", err)
 	}
 
 	pageSize := int64(request.PageSize)
 	offset := pageSize * int64(request.Page-1)
-	cursor, err := coll.Find(ctx, bson.M{}, options.Find().SetLimit(pageSize).SetSkip(offset).SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	cursor, err := coll.Find(ctx, filter, options.Find().SetLimit(pageSize).SetSkip(offset).SetSort(buildSort(request.Query)))
 	if err != nil {
-		log.Printf("Failed to find documents in collection %s.%s: %v", u.database, u.collection, err)
+		slog.ErrorContext(ctx, "Failed to find documents", slog.String("collection", u.database+"."+u.collection), logger.Error(err))
 		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	portservice "codetest/internal/port/service"
+)
+
+// HealthCheck reports the state of one dependency, a nil error meaning it is
+// usable.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type healthService struct {
+	checks   []HealthCheck
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	shuttingDown atomic.Bool
+
+	mu        sync.Mutex
+	report    *dto.HealthReport
+	expiresAt time.Time
+}
+
+// NewHealthService runs checks concurrently, each bounded by timeout. Results
+// are reused for cacheTTL so frequent probes do not hammer the dependencies.
+func NewHealthService(checks []HealthCheck, timeout, cacheTTL time.Duration) portservice.HealthService {
+	return &healthService{
+		checks:   checks,
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+	}
+}
+
+// Ready implements portservice.HealthService.
+func (h *healthService) Ready(ctx context.Context) *dto.HealthReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.report == nil || time.Now().After(h.expiresAt) {
+		h.report = h.check(ctx)
+		h.expiresAt = time.Now().Add(h.cacheTTL)
+	}
+
+	report := *h.report
+	if h.shuttingDown.Load() {
+		report.Status = dto.HealthStatusShuttingDown
+	}
+
+	return &report
+}
+
+// SetShuttingDown implements portservice.HealthService.
+func (h *healthService) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+func (h *healthService) check(ctx context.Context) *dto.HealthReport {
+	report := &dto.HealthReport{
+		Status:     dto.HealthStatusUp,
+		Components: make(map[string]dto.HealthComponent, len(h.checks)),
+		CheckedAt:  time.Now(),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	// Probes must not be cut short by the client disconnecting, the result
+	// is shared with later callers.
+	ctx = context.WithoutCancel(ctx)
+
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+
+			// A check ignoring its context still cannot hold the probe.
+			result := make(chan error, 1)
+			go func() {
+				result <- check.Check(checkCtx)
+			}()
+
+			var err error
+			select {
+			case err = <-result:
+			case <-checkCtx.Done():
+				err = checkCtx.Err()
+			}
+
+			component := dto.HealthComponent{
+				Status:    dto.HealthStatusUp,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				component.Status = dto.HealthStatusDown
+				component.Error = err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Components[check.Name] = component
+			if err != nil {
+				report.Status = dto.HealthStatusDown
+			}
+		}()
+	}
+
+	wg.Wait()
+	return report
+}
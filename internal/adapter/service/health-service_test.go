@@ -0,0 +1,100 @@
+package service
+
+import (
+	"codetest/internal/adapter/api/dto"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealthService_Ready(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("all components up", func(t *testing.T) {
+		healthService := NewHealthService([]HealthCheck{
+			{Name: "postgres", Check: func(ctx context.Context) error { return nil }},
+			{Name: "redis", Check: func(ctx context.Context) error { return nil }},
+		}, time.Second, 0)
+
+		report := healthService.Ready(ctx)
+		if report.Status != dto.HealthStatusUp {
+			t.Errorf("expected status up, got %s", report.Status)
+		}
+
+		if len(report.Components) != 2 || report.Components["redis"].Status != dto.HealthStatusUp {
+			t.Errorf("unexpected components %+v", report.Components)
+		}
+	})
+
+	t.Run("one failing component fails the report", func(t *testing.T) {
+		healthService := NewHealthService([]HealthCheck{
+			{Name: "postgres", Check: func(ctx context.Context) error { return nil }},
+			{Name: "mongodb", Check: func(ctx context.Context) error { return errors.New("connection refused") }},
+		}, time.Second, 0)
+
+		report := healthService.Ready(ctx)
+		if report.Status != dto.HealthStatusDown {
+			t.Errorf("expected status down, got %s", report.Status)
+		}
+
+		if component := report.Components["mongodb"]; component.Status != dto.HealthStatusDown || component.Error != "connection refused" {
+			t.Errorf("unexpected mongodb component %+v", component)
+		}
+
+		if report.Components["postgres"].Status != dto.HealthStatusUp {
+			t.Errorf("expected postgres to stay up, got %+v", report.Components["postgres"])
+		}
+	})
+
+	t.Run("slow component times out", func(t *testing.T) {
+		healthService := NewHealthService([]HealthCheck{
+			{Name: "redis", Check: func(ctx context.Context) error {
+				time.Sleep(time.Second)
+				return nil
+			}},
+		}, 20*time.Millisecond, 0)
+
+		start := time.Now()
+		report := healthService.Ready(ctx)
+
+		if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+			t.Errorf("expected the check to be cut at the timeout, took %s", elapsed)
+		}
+
+		if report.Components["redis"].Error != context.DeadlineExceeded.Error() {
+			t.Errorf("expected deadline exceeded, got %+v", report.Components["redis"])
+		}
+	})
+
+	t.Run("results are cached", func(t *testing.T) {
+		var calls atomic.Int32
+		healthService := NewHealthService([]HealthCheck{
+			{Name: "postgres", Check: func(ctx context.Context) error {
+				calls.Add(1)
+				return nil
+			}},
+		}, time.Second, time.Minute)
+
+		healthService.Ready(ctx)
+		healthService.Ready(ctx)
+
+		if calls.Load() != 1 {
+			t.Errorf("expected one check, got %d", calls.Load())
+		}
+	})
+
+	t.Run("shutting down fails readiness", func(t *testing.T) {
+		healthService := NewHealthService([]HealthCheck{
+			{Name: "postgres", Check: func(ctx context.Context) error { return nil }},
+		}, time.Second, time.Minute)
+
+		healthService.Ready(ctx)
+		healthService.SetShuttingDown()
+
+		if report := healthService.Ready(ctx); report.Status != dto.HealthStatusShuttingDown {
+			t.Errorf("expected status shutting_down, got %s", report.Status)
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	portservice "codetest/internal/port/service"
+
+	"github.com/google/uuid"
+)
+
+var ErrJobFinished = errors.New("job already finished")
+
+type jobService struct {
+	jobRepository portrepository.JobRepository
+	artifactDir   string
+}
+
+func NewJobService(jobRepository portrepository.JobRepository, artifactDir string) portservice.JobService {
+	return &jobService{
+		jobRepository: jobRepository,
+		artifactDir:   artifactDir,
+	}
+}
+
+// Enqueue implements portservice.JobService.
+func (j *jobService) Enqueue(ctx context.Context, jobType model.JobType, payload interface{}, input io.Reader, createdBy string) (*model.JobModel, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &model.JobModel{
+		ID:        uuid.New(),
+		Type:      jobType,
+		Status:    model.JobStatusQueued,
+		Payload:   payloadBytes,
+		CreatedBy: createdBy,
+	}
+
+	if input != nil {
+		if job.InputPath, err = j.saveInput(job.ID, input); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := j.jobRepository.Create(ctx, job); err != nil {
+		if job.InputPath != "" {
+			_ = os.Remove(job.InputPath)
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetOneByID implements portservice.JobService.
+func (j *jobService) GetOneByID(ctx context.Context, id uuid.UUID) (*model.JobModel, error) {
+	job, err := j.jobRepository.GetOneBy(ctx, "id", id.String())
+	if err != nil {
+		return nil, err
+	}
+
+	job.HasArtifact = job.Status == model.JobStatusSucceeded && artifactExists(job.ArtifactPath)
+	return job, nil
+}
+
+// artifactExists reports whether the artifact at path is still on disk, the
+// worker sweeps artifacts once JOB_ARTIFACT_RETENTION has passed.
+func artifactExists(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Cancel implements portservice.JobService.
+func (j *jobService) Cancel(ctx context.Context, id uuid.UUID) (*model.JobModel, error) {
+	job, err := j.jobRepository.GetOneBy(ctx, "id", id.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status.Finished() {
+		return job, ErrJobFinished
+	}
+
+	if err := j.jobRepository.RequestCancel(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (j *jobService) saveInput(id uuid.UUID, input io.Reader) (string, error) {
+	if err := os.MkdirAll(j.artifactDir, 0o750); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(j.artifactDir, id.String()+".input")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, input); err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+
+	return path, nil
+}
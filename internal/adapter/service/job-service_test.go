@@ -0,0 +1,106 @@
+package service
+
+import (
+	"codetest/internal/model"
+	"codetest/mocks/repository"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJobService_Enqueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobRepo := repository.NewMockJobRepository(ctrl)
+	jobService := NewJobService(mockJobRepo, t.TempDir())
+
+	ctx := context.Background()
+
+	t.Run("input is saved next to the job", func(t *testing.T) {
+		mockJobRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+
+		job, err := jobService.Enqueue(ctx, model.JobTypeUserImport, map[string]string{"format": "csv"}, strings.NewReader("name,email,password\n"), "creator")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if job.Status != model.JobStatusQueued {
+			t.Errorf("expected queued job, got %s", job.Status)
+		}
+
+		if string(job.Payload) != `{"format":"csv"}` {
+			t.Errorf("unexpected payload %s", job.Payload)
+		}
+
+		input, err := os.ReadFile(job.InputPath)
+		if err != nil || string(input) != "name,email,password\n" {
+			t.Errorf("expected input to be saved, got %q (%v)", input, err)
+		}
+	})
+
+	t.Run("input is removed when the job cannot be stored", func(t *testing.T) {
+		var inputPath string
+		mockJobRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, job *model.JobModel) error {
+			inputPath = job.InputPath
+			return errors.New("database error")
+		})
+
+		if _, err := jobService.Enqueue(ctx, model.JobTypeUserImport, nil, strings.NewReader("data"), "creator"); err == nil {
+			t.Fatal("expected an error")
+		}
+
+		if _, err := os.Stat(inputPath); !os.IsNotExist(err) {
+			t.Errorf("expected input %s to be removed", inputPath)
+		}
+	})
+}
+
+func TestJobService_Cancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockJobRepo := repository.NewMockJobRepository(ctrl)
+	jobService := NewJobService(mockJobRepo, t.TempDir())
+
+	ctx := context.Background()
+	jobID := uuid.New()
+
+	tests := []struct {
+		name          string
+		status        model.JobStatus
+		setupMock     func()
+		expectedError error
+	}{
+		{
+			name:   "queued job is cancelled",
+			status: model.JobStatusQueued,
+			setupMock: func() {
+				mockJobRepo.EXPECT().RequestCancel(ctx, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:          "finished job cannot be cancelled",
+			status:        model.JobStatusSucceeded,
+			setupMock:     func() {},
+			expectedError: ErrJobFinished,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockJobRepo.EXPECT().GetOneBy(ctx, "id", jobID.String()).Return(&model.JobModel{ID: jobID, Status: tt.status}, nil)
+			tt.setupMock()
+
+			_, err := jobService.Cancel(ctx, jobID)
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
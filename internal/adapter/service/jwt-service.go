@@ -29,7 +29,7 @@ func (j *jwtService) GenerateAccessToken(user *model.UserModel) (string, error)
 	claims := &JWTClaims{
 		UserID: user.ID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * time.Duration(j.cfg.ACCESS_TOKEN_TTL))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.cfg.ACCESS_TOKEN_TTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -64,7 +64,7 @@ func (j *jwtService) GenerateRefreshToken(user *model.UserModel) (string, error)
 	claims := &JWTClaims{
 		UserID: user.ID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second * time.Duration(j.cfg.REFRESH_TOKEN_TTL))),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.cfg.REFRESH_TOKEN_TTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -101,8 +101,13 @@ func (j *jwtService) RefreshToken(refreshToken string) (accessTk, refreshTk stri
 		return "", "", err
 	}
 
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return "", "", err
+	}
+
 	user := &model.UserModel{
-		ID: uuid.MustParse(userID),
+		ID: id,
 	}
 
 	aTk, err := j.GenerateAccessToken(user)
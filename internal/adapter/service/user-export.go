@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/adapter/publisher"
+	"codetest/internal/model"
+	"codetest/internal/query"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// exportFlushInterval is the number of rows buffered before they are pushed
+// to the writer, keeping memory flat and the client receiving data.
+const exportFlushInterval = 100
+
+var ErrUnsupportedExportFormat = errors.New("export format must be csv, ndjson or xlsx")
+
+type userExportWriter interface {
+	Write(user *model.UserModel) error
+	Flush() error
+	Close() error
+}
+
+// Export streams every user matching request to writer in the requested
+// format and returns the number of exported users.
+func (u *userService) Export(ctx context.Context, request *dto.ExportUserRequest, writer io.Writer) (int, error) {
+	columns := request.SelectColumns
+	if len(columns) == 0 {
+		columns = dto.UserExportColumns
+	}
+
+	exportWriter, err := newUserExportWriter(request.Format, writer, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	err = u.userRepository.Stream(ctx, request.ToQueryUserRequest(), func(user *model.UserModel) error {
+		if err := exportWriter.Write(user); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushInterval == 0 {
+			return exportWriter.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := exportWriter.Close(); err != nil {
+		return count, err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventExport, map[string]interface{}{
+		"format":  request.Format,
+		"columns": columns,
+		"search":  request.Search,
+		"deleted": request.Deleted,
+		"filter":  describeConditions(request.Query),
+		"count":   count,
+	})
+	return count, nil
+}
+
+// describeConditions renders the filters of an export for its audit event.
+func describeConditions(q *query.Query) []string {
+	if q == nil {
+		return nil
+	}
+
+	conditions := make([]string, 0, len(q.Conditions))
+	for _, cond := range q.Conditions {
+		conditions = append(conditions, fmt.Sprintf("%s %s %v", cond.Field, cond.Operator, cond.Value))
+	}
+	return conditions
+}
+
+func newUserExportWriter(format string, writer io.Writer, columns []string) (userExportWriter, error) {
+	switch format {
+	case dto.ExportFormatCSV, "":
+		return newCSVUserExportWriter(writer, columns)
+	case dto.ExportFormatNDJSON:
+		return &ndjsonUserExportWriter{writer: writer, encoder: json.NewEncoder(writer), columns: columns}, nil
+	case dto.ExportFormatXLSX:
+		return newXLSXUserExportWriter(writer, columns)
+	default:
+		return nil, ErrUnsupportedExportFormat
+	}
+}
+
+func userColumnValue(user *model.UserModel, column string) interface{} {
+	switch column {
+	case "id":
+		return user.ID.String()
+	case "name":
+		return user.Name
+	case "email":
+		return user.Email
+	case "role":
+		return user.Role.String()
+	case "version":
+		return user.Version
+	case "created_at":
+		return user.CreatedAt.UTC().Format(time.RFC3339)
+	case "updated_at":
+		return user.UpdatedAt.UTC().Format(time.RFC3339)
+	case "deleted_at":
+		if !user.DeletedAt.Valid {
+			return nil
+		}
+		return user.DeletedAt.Time.UTC().Format(time.RFC3339)
+	default:
+		return nil
+	}
+}
+
+type csvUserExportWriter struct {
+	writer  *csv.Writer
+	columns []string
+	record  []string
+}
+
+func newCSVUserExportWriter(writer io.Writer, columns []string) (*csvUserExportWriter, error) {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(columns); err != nil {
+		return nil, err
+	}
+
+	return &csvUserExportWriter{
+		writer:  csvWriter,
+		columns: columns,
+		record:  make([]string, len(columns)),
+	}, nil
+}
+
+func (w *csvUserExportWriter) Write(user *model.UserModel) error {
+	for i, column := range w.columns {
+		switch value := userColumnValue(user, column).(type) {
+		case nil:
+			w.record[i] = ""
+		case int:
+			w.record[i] = strconv.Itoa(value)
+		case string:
+			w.record[i] = escapeCSVFormula(value)
+		}
+	}
+
+	return w.writer.Write(w.record)
+}
+
+// escapeCSVFormula prefixes values a spreadsheet would evaluate as a formula
+// with a quote, so a user named "=HYPERLINK(...)" stays plain text.
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (w *csvUserExportWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+func (w *csvUserExportWriter) Close() error {
+	return w.Flush()
+}
+
+type ndjsonUserExportWriter struct {
+	writer  io.Writer
+	encoder *json.Encoder
+	columns []string
+}
+
+func (w *ndjsonUserExportWriter) Write(user *model.UserModel) error {
+	row := make(map[string]interface{}, len(w.columns))
+	for _, column := range w.columns {
+		row[column] = userColumnValue(user, column)
+	}
+
+	return w.encoder.Encode(row)
+}
+
+func (w *ndjsonUserExportWriter) Flush() error {
+	return nil
+}
+
+func (w *ndjsonUserExportWriter) Close() error {
+	return nil
+}
+
+// xlsxUserExportWriter uses excelize's stream writer, which spills rows to a
+// temporary file, the workbook itself can only be written once complete.
+type xlsxUserExportWriter struct {
+	writer  io.Writer
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	columns []string
+	row     int
+}
+
+func newXLSXUserExportWriter(writer io.Writer, columns []string) (*xlsxUserExportWriter, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+
+	if err := stream.SetRow("A1", header); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &xlsxUserExportWriter{
+		writer:  writer,
+		file:    file,
+		stream:  stream,
+		columns: columns,
+		row:     1,
+	}, nil
+}
+
+func (w *xlsxUserExportWriter) Write(user *model.UserModel) error {
+	values := make([]interface{}, len(w.columns))
+	for i, column := range w.columns {
+		values[i] = userColumnValue(user, column)
+	}
+
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxUserExportWriter) Flush() error {
+	return nil
+}
+
+func (w *xlsxUserExportWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+
+	return w.file.Write(w.writer)
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/adapter/publisher"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	importBatchSize = 100
+	maxImportRows   = 10000
+)
+
+var (
+	ErrUnsupportedImportFormat = errors.New("import format must be csv or ndjson")
+	ErrTooManyImportRows       = fmt.Errorf("import is limited to %d rows", maxImportRows)
+)
+
+// importValidator applies the same `binding` rules gin uses for
+// CreateUserRequest, reporting fields by their json name.
+var importValidator = func() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}()
+
+type importRow struct {
+	number  int
+	request dto.CreateUserRequest
+	errors  map[string]string
+	user    *model.UserModel
+}
+
+// Import validates every row with the CreateUserRequest rules and, unless
+// dryRun is set, creates the valid ones in batched transactions. fileName is
+// only recorded in the audit event.
+func (u *userService) Import(ctx context.Context, reader io.Reader, fileName, format string, dryRun bool) (*dto.ImportUserReport, error) {
+	rows, err := decodeImportRows(reader, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.validateImportRows(ctx, rows); err != nil {
+		return nil, err
+	}
+
+	valid := make([]*importRow, 0, len(rows))
+	for _, row := range rows {
+		if len(row.errors) == 0 {
+			valid = append(valid, row)
+		}
+	}
+
+	if !dryRun {
+		if err := hashImportPasswords(ctx, valid, u.hashCost); err != nil {
+			return nil, err
+		}
+
+		for start := 0; start < len(valid); start += importBatchSize {
+			end := min(start+importBatchSize, len(valid))
+			u.insertImportBatch(ctx, valid[start:end])
+		}
+	}
+
+	report := &dto.ImportUserReport{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Rows:   make([]dto.ImportUserRowResult, 0, len(rows)),
+	}
+
+	for _, row := range rows {
+		success := len(row.errors) == 0
+		if success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+
+		report.Rows = append(report.Rows, dto.ImportUserRowResult{
+			Row:     row.number,
+			Email:   row.request.Email,
+			Success: success,
+			Errors:  row.errors,
+		})
+	}
+
+	if !dryRun && report.Succeeded > 0 {
+		emails := make([]string, 0, len(valid))
+		for _, row := range valid {
+			if len(row.errors) == 0 {
+				emails = append(emails, row.request.Email)
+			}
+		}
+
+		publisher.Emit(ctx, u.events, model.UserLogEventImport, map[string]interface{}{
+			"file":   fileName,
+			"total":  report.Total,
+			"failed": report.Failed,
+			"emails": emails,
+		})
+	}
+
+	return report, nil
+}
+
+func (u *userService) validateImportRows(ctx context.Context, rows []*importRow) error {
+	seen := make(map[string]int, len(rows))
+	emails := make([]string, 0, len(rows))
+
+	for _, row := range rows {
+		if row.errors != nil {
+			continue
+		}
+
+		if err := importValidator.Struct(&row.request); err != nil {
+			var validationErrors validator.ValidationErrors
+			if !errors.As(err, &validationErrors) {
+				return err
+			}
+
+			row.errors = make(map[string]string, len(validationErrors))
+			for _, e := range validationErrors {
+				row.errors[e.Field()] = dto.ValidationMessage(e.Field(), e.Tag(), e.Param())
+			}
+			continue
+		}
+
+		// Addresses differing only in case belong to the same mailbox.
+		row.request.Email = strings.ToLower(row.request.Email)
+
+		if first, ok := seen[row.request.Email]; ok {
+			row.errors = map[string]string{"email": fmt.Sprintf("email is duplicated in row %d", first)}
+			continue
+		}
+
+		seen[row.request.Email] = row.number
+		emails = append(emails, row.request.Email)
+	}
+
+	existing := make(map[string]bool)
+	for start := 0; start < len(emails); start += importBatchSize {
+		end := min(start+importBatchSize, len(emails))
+
+		found, err := u.userRepository.ExistingEmails(ctx, emails[start:end])
+		if err != nil {
+			return err
+		}
+
+		for _, email := range found {
+			existing[email] = true
+		}
+	}
+
+	for _, row := range rows {
+		if row.errors == nil && existing[row.request.Email] {
+			row.errors = map[string]string{"email": "email already exists"}
+		}
+	}
+
+	return nil
+}
+
+// hashImportPasswords hashes with one worker per CPU, bcrypt dominates the
+// cost of an import.
+func hashImportPasswords(ctx context.Context, rows []*importRow, cost int) error {
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(runtime.GOMAXPROCS(0))
+
+	for _, row := range rows {
+		g.Go(func() error {
+			if err := gCtx.Err(); err != nil {
+				return err
+			}
+
+			passBytes, err := bcrypt.GenerateFromPassword([]byte(row.request.Password), cost)
+			if err != nil {
+				return err
+			}
+
+			row.user = &model.UserModel{
+				Name:     row.request.Name,
+				Email:    row.request.Email,
+				Password: string(passBytes),
+			}
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// insertImportBatch creates the batch in one transaction. When that fails the
+// rows are retried one by one so the report can point at the culprit.
+func (u *userService) insertImportBatch(ctx context.Context, batch []*importRow) {
+	users := make([]*model.UserModel, 0, len(batch))
+	for _, row := range batch {
+		users = append(users, row.user)
+	}
+
+	if err := u.userRepository.CreateMany(ctx, users); err == nil {
+		return
+	}
+
+	for _, row := range batch {
+		err := u.userRepository.Create(ctx, row.user)
+		switch {
+		case err == nil:
+		case errors.Is(err, portrepository.ErrAlreadyExists):
+			row.errors = map[string]string{"email": "email already exists"}
+		default:
+			row.errors = map[string]string{"row": "failed to create user: " + err.Error()}
+		}
+	}
+}
+
+func decodeImportRows(reader io.Reader, format string) ([]*importRow, error) {
+	switch format {
+	case dto.ImportFormatCSV:
+		return decodeCSVRows(reader)
+	case dto.ImportFormatNDJSON:
+		return decodeNDJSONRows(reader)
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+}
+
+// decodeCSVRows expects a header with name, email and password columns and an
+// optional confirm_password column, which defaults to password.
+func decodeCSVRows(reader io.Reader) ([]*importRow, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.TrimLeadingSpace = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	for _, required := range []string{"name", "email", "password"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing the %s column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []*importRow
+	for number := 1; ; number++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if number > maxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		row := &importRow{number: number}
+		rows = append(rows, row)
+
+		if err != nil {
+			if !errors.Is(err, csv.ErrFieldCount) {
+				return nil, fmt.Errorf("failed to read csv row %d: %w", number, err)
+			}
+			row.errors = map[string]string{"row": "row has the wrong number of columns"}
+			continue
+		}
+
+		row.request = dto.CreateUserRequest{
+			Name:            field(record, "name"),
+			Email:           field(record, "email"),
+			Password:        field(record, "password"),
+			ConfirmPassword: field(record, "confirm_password"),
+		}
+		if row.request.ConfirmPassword == "" {
+			row.request.ConfirmPassword = row.request.Password
+		}
+	}
+
+	return rows, nil
+}
+
+// decodeNDJSONRows expects one CreateUserRequest object per line, blank lines
+// are skipped.
+func decodeNDJSONRows(reader io.Reader) ([]*importRow, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var rows []*importRow
+	for number := 0; scanner.Scan(); {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		number++
+		if number > maxImportRows {
+			return nil, ErrTooManyImportRows
+		}
+
+		row := &importRow{number: number}
+		rows = append(rows, row)
+
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row.request); err != nil {
+			row.errors = map[string]string{"row": "invalid JSON: " + err.Error()}
+			continue
+		}
+
+		if row.request.ConfirmPassword == "" {
+			row.request.ConfirmPassword = row.request.Password
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ndjson: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+)
+
+type userImportJobProcessor struct {
+	userService portservice.UserService
+}
+
+func NewUserImportJobProcessor(userService portservice.UserService) portservice.JobProcessor {
+	return &userImportJobProcessor{
+		userService: userService,
+	}
+}
+
+// Process implements portservice.JobProcessor.
+func (p *userImportJobProcessor) Process(ctx context.Context, job *model.JobModel, progress portservice.JobProgressFunc) (interface{}, error) {
+	var payload dto.UserImportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	file, err := os.Open(job.InputPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	progress(0, 1)
+
+	report, err := p.userService.Import(ctx, file, payload.FileName, payload.Format, payload.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	progress(1, 1)
+	return report, nil
+}
+
+type userExportJobProcessor struct {
+	userService portservice.UserService
+	artifactDir string
+}
+
+func NewUserExportJobProcessor(userService portservice.UserService, artifactDir string) portservice.JobProcessor {
+	return &userExportJobProcessor{
+		userService: userService,
+		artifactDir: artifactDir,
+	}
+}
+
+// Process implements portservice.JobProcessor. The export is written to a
+// file in the artifact directory instead of the HTTP response.
+func (p *userExportJobProcessor) Process(ctx context.Context, job *model.JobModel, progress portservice.JobProgressFunc) (interface{}, error) {
+	var payload dto.UserExportJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	values, err := url.ParseQuery(payload.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	request := &dto.ExportUserRequest{
+		Format:  values.Get("format"),
+		Columns: values.Get("columns"),
+		Search:  values.Get("search"),
+		Deleted: values.Get("deleted"),
+		Sort:    values.Get("sort"),
+	}
+	if request.Format == "" {
+		request.Format = dto.ExportFormatCSV
+	}
+
+	if err := request.ParseQuery(values); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(p.artifactDir, 0o750); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(p.artifactDir, job.ID.String()+"."+request.Format)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	progress(0, 1)
+
+	count, err := p.userService.Export(ctx, request, file)
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	job.ArtifactPath = path
+	progress(1, 1)
+
+	return map[string]interface{}{
+		"count":   count,
+		"format":  request.Format,
+		"columns": request.SelectColumns,
+	}, nil
+}
+
+type userPurgeJobProcessor struct {
+	userService portservice.UserService
+}
+
+func NewUserPurgeJobProcessor(userService portservice.UserService) portservice.JobProcessor {
+	return &userPurgeJobProcessor{
+		userService: userService,
+	}
+}
+
+// Process implements portservice.JobProcessor. It permanently removes users
+// that stayed in the trash longer than the retention period.
+func (p *userPurgeJobProcessor) Process(ctx context.Context, job *model.JobModel, progress portservice.JobProgressFunc) (interface{}, error) {
+	var payload dto.PurgeUsersRequest
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid payload: %w", err)
+	}
+
+	before := time.Now().AddDate(0, 0, -payload.OlderThanDays)
+
+	progress(0, 1)
+
+	purged, err := p.userService.PurgeDeleted(ctx, before)
+	if err != nil {
+		return nil, err
+	}
+
+	progress(1, 1)
+
+	return map[string]interface{}{
+		"purged":         purged,
+		"deleted_before": before.UTC().Format(time.RFC3339),
+	}, nil
+}
+
+type userRehashJobProcessor struct {
+	userService portservice.UserService
+}
+
+func NewUserRehashJobProcessor(userService portservice.UserService) portservice.JobProcessor {
+	return &userRehashJobProcessor{
+		userService: userService,
+	}
+}
+
+// Process implements portservice.JobProcessor. It counts the password hashes
+// made with a bcrypt cost below PASSWORD_HASH_COST. Those are re-hashed when
+// their owner next logs in, the only time the password is known.
+func (p *userRehashJobProcessor) Process(ctx context.Context, job *model.JobModel, progress portservice.JobProgressFunc) (interface{}, error) {
+	progress(0, 1)
+
+	checked, outdated, err := p.userService.CountOutdatedPasswordHashes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	progress(1, 1)
+
+	return map[string]interface{}{
+		"checked":  checked,
+		"outdated": outdated,
+	}, nil
+}
@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"html"
+	"strings"
+	"time"
 
 	"codetest/internal/adapter/api/dto"
+	"codetest/internal/adapter/publisher"
 	"codetest/internal/model"
 	portrepository "codetest/internal/port/repository"
 	portservice "codetest/internal/port/service"
@@ -14,11 +18,18 @@ import (
 
 type userService struct {
 	userRepository portrepository.UserRepository
+	events         portservice.EventPublisher
+	hashCost       int
 }
 
-func NewUserService(userRepository portrepository.UserRepository) portservice.UserService {
+// NewUserService returns the user service. Every change it makes is
+// published to events on behalf of requestctx.Actor, passwords are hashed
+// with bcrypt at hashCost.
+func NewUserService(userRepository portrepository.UserRepository, events portservice.EventPublisher, hashCost int) portservice.UserService {
 	return &userService{
 		userRepository: userRepository,
+		events:         events,
+		hashCost:       hashCost,
 	}
 }
 
@@ -28,13 +39,88 @@ func (u *userService) Create(ctx context.Context, request *dto.CreateUserRequest
 		Email: request.Email,
 	}
 
-	passBytes, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	passBytes, err := bcrypt.GenerateFromPassword([]byte(request.Password), u.hashCost)
 	if err != nil {
 		return err
 	}
 	user.Password = string(passBytes)
 
-	return u.userRepository.Create(ctx, user)
+	if err := u.userRepository.Create(ctx, user); err != nil {
+		return err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventCreate, map[string]interface{}{
+		"id":    user.ID,
+		"email": user.Email,
+		"name":  user.Name,
+	})
+	return nil
+}
+
+// CreateMany creates the users whose email is not taken yet in batches and
+// returns how many it created. Unlike Import it does not validate the
+// requests, and requests sharing a password share its hash so that seeding
+// thousands of users does not spend minutes in bcrypt.
+func (u *userService) CreateMany(ctx context.Context, requests []*dto.CreateUserRequest) (int, error) {
+	created, err := u.createMany(ctx, requests)
+	if created > 0 {
+		publisher.Emit(ctx, u.events, model.UserLogEventImport, map[string]interface{}{
+			"total":     len(requests),
+			"succeeded": created,
+		})
+	}
+	return created, err
+}
+
+func (u *userService) createMany(ctx context.Context, requests []*dto.CreateUserRequest) (int, error) {
+	hashes := make(map[string]string)
+	created := 0
+	for start := 0; start < len(requests); start += importBatchSize {
+		batch := requests[start:min(start+importBatchSize, len(requests))]
+
+		emails := make([]string, 0, len(batch))
+		for _, request := range batch {
+			emails = append(emails, strings.ToLower(request.Email))
+		}
+
+		existing, err := u.userRepository.ExistingEmails(ctx, emails)
+		if err != nil {
+			return created, err
+		}
+		taken := make(map[string]bool, len(existing))
+		for _, email := range existing {
+			taken[email] = true
+		}
+
+		users := make([]*model.UserModel, 0, len(batch))
+		for i, request := range batch {
+			if taken[emails[i]] {
+				continue
+			}
+
+			hash, ok := hashes[request.Password]
+			if !ok {
+				passBytes, err := bcrypt.GenerateFromPassword([]byte(request.Password), u.hashCost)
+				if err != nil {
+					return created, err
+				}
+				hash = string(passBytes)
+				hashes[request.Password] = hash
+			}
+
+			users = append(users, &model.UserModel{Name: request.Name, Email: emails[i], Password: hash})
+		}
+		if len(users) == 0 {
+			continue
+		}
+
+		if err := u.userRepository.CreateMany(ctx, users); err != nil {
+			return created, err
+		}
+		created += len(users)
+	}
+
+	return created, nil
 }
 
 func (u *userService) Find(ctx context.Context, request *dto.QueryUserRequest) ([]*model.UserModel, int64, error) {
@@ -43,6 +129,12 @@ func (u *userService) Find(ctx context.Context, request *dto.QueryUserRequest) (
 		return nil, 0, err
 	}
 
+	if request.Search != "" {
+		for _, user := range users {
+			user.SearchHighlight = highlightMatch(user, request.Search)
+		}
+	}
+
 	return users, total, nil
 }
 
@@ -54,24 +146,171 @@ func (u *userService) GetOneByEmail(ctx context.Context, email string) (*model.U
 	return u.userRepository.GetOneBy(ctx, "email", email)
 }
 
+func (u *userService) SetRole(ctx context.Context, id uuid.UUID, role model.UserRole) error {
+	if err := u.userRepository.UpdateColumnsBy(ctx, "id", id.String(), map[string]interface{}{"role": role}); err != nil {
+		return err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventUpdate, map[string]interface{}{"id": id, "action": "set-role", "role": role})
+	return nil
+}
+
+func (u *userService) ResetPassword(ctx context.Context, id uuid.UUID, password string) error {
+	passBytes, err := bcrypt.GenerateFromPassword([]byte(password), u.hashCost)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepository.UpdateColumnsBy(ctx, "id", id.String(), map[string]interface{}{"password": string(passBytes)}); err != nil {
+		return err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventUpdate, map[string]interface{}{"id": id, "action": "reset-password"})
+	return nil
+}
+
+// UpgradePasswordHash re-hashes password at the current cost when the hash
+// stored for user is cheaper. The caller must have checked password against
+// that hash, bcrypt hashes cannot be upgraded without the plain text.
+func (u *userService) UpgradePasswordHash(ctx context.Context, user *model.UserModel, password string) error {
+	if !passwordHashOutdated(user.Password, u.hashCost) {
+		return nil
+	}
+
+	passBytes, err := bcrypt.GenerateFromPassword([]byte(password), u.hashCost)
+	if err != nil {
+		return err
+	}
+
+	if err := u.userRepository.UpdateColumnsBy(ctx, "id", user.ID.String(), map[string]interface{}{"password": string(passBytes)}); err != nil {
+		return err
+	}
+	user.Password = string(passBytes)
+
+	publisher.Emit(ctx, u.events, model.UserLogEventUpdate, map[string]interface{}{"id": user.ID, "action": "rehash-password", "cost": u.hashCost})
+	return nil
+}
+
+// CountOutdatedPasswordHashes walks every user, soft deleted ones included,
+// and counts the password hashes cheaper than the current cost.
+func (u *userService) CountOutdatedPasswordHashes(ctx context.Context) (int64, int64, error) {
+	var checked, outdated int64
+	err := u.userRepository.Stream(ctx, &dto.QueryUserRequest{Deleted: dto.DeletedInclude}, func(user *model.UserModel) error {
+		checked++
+		if passwordHashOutdated(user.Password, u.hashCost) {
+			outdated++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return checked, outdated, nil
+}
+
+// passwordHashOutdated reports whether hash was made with a cost below cost.
+// Hashes bcrypt cannot read are left alone.
+func passwordHashOutdated(hash string, cost int) bool {
+	hashCost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && hashCost < cost
+}
+
+// Disable blocks the user from logging in again. Access tokens already
+// issued stay valid until they expire.
+func (u *userService) Disable(ctx context.Context, id uuid.UUID) error {
+	if err := u.userRepository.UpdateColumnsBy(ctx, "id", id.String(), map[string]interface{}{"disabled_at": time.Now()}); err != nil {
+		return err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventUpdate, map[string]interface{}{"id": id, "action": "disable"})
+	return nil
+}
+
 func (u *userService) DeleteOneByID(ctx context.Context, id uuid.UUID) error {
-	return u.userRepository.DeleteOneBy(ctx, "id", id.String())
+	if err := u.userRepository.DeleteOneBy(ctx, "id", id.String()); err != nil {
+		return err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventDelete, map[string]interface{}{"id": id})
+	return nil
+}
+
+func (u *userService) RestoreOneByID(ctx context.Context, id uuid.UUID) error {
+	if err := u.userRepository.RestoreOneBy(ctx, "id", id.String()); err != nil {
+		return err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventRestore, map[string]interface{}{"id": id})
+	return nil
+}
+
+func (u *userService) PurgeOneByID(ctx context.Context, id uuid.UUID) error {
+	if err := u.userRepository.PurgeOneBy(ctx, "id", id.String()); err != nil {
+		return err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventPurge, map[string]interface{}{"id": id})
+	return nil
 }
 
-func (u *userService) Update(ctx context.Context, id uuid.UUID, request *dto.UpdateUserRequest) error {
+// PurgeDeleted permanently removes the users soft deleted before before.
+func (u *userService) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	purged, err := u.userRepository.PurgeDeletedBefore(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventPurge, map[string]interface{}{
+		"deleted_before": before.UTC().Format(time.RFC3339),
+		"purged":         purged,
+	})
+	return purged, nil
+}
+
+func (u *userService) Update(ctx context.Context, id uuid.UUID, version int, request *dto.UpdateUserRequest) (*model.UserModel, error) {
 	user := &model.UserModel{
-		ID:    id,
-		Name:  request.Name,
-		Email: request.Email,
+		ID:      id,
+		Name:    request.Name,
+		Email:   request.Email,
+		Version: version,
 	}
 
 	if len(request.Password) > 0 {
-		passBytes, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		passBytes, err := bcrypt.GenerateFromPassword([]byte(request.Password), u.hashCost)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		user.Password = string(passBytes)
 	}
 
-	return u.userRepository.Update(ctx, user)
+	if err := u.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	publisher.Emit(ctx, u.events, model.UserLogEventUpdate, map[string]interface{}{
+		"id":    id,
+		"email": user.Email,
+		"name":  user.Name,
+	})
+	return user, nil
+}
+
+// highlightMatch wraps the searched term in <mark> within the field the
+// repository reported as the best match, escaping the rest of the value.
+// Fuzzy matches without an exact substring highlight the whole field.
+func highlightMatch(user *model.UserModel, term string) string {
+	value := user.Name
+	if user.SearchField == "email" {
+		value = user.Email
+	}
+
+	lowerValue, lowerTerm := strings.ToLower(value), strings.ToLower(term)
+	index := strings.Index(lowerValue, lowerTerm)
+	if index < 0 || len(lowerValue) != len(value) || len(lowerTerm) != len(term) {
+		return "<mark>" + html.EscapeString(value) + "</mark>"
+	}
+
+	end := index + len(term)
+	return html.EscapeString(value[:index]) + "<mark>" + html.EscapeString(value[index:end]) + "</mark>" + html.EscapeString(value[end:])
 }
@@ -2,12 +2,17 @@ package service
 
 import (
 	"codetest/internal/adapter/api/dto"
+	"codetest/internal/adapter/publisher"
 	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	"codetest/internal/requestctx"
 	"codetest/mocks/repository"
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"go.uber.org/mock/gomock"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -17,7 +22,7 @@ func TestUserService_Create(t *testing.T) {
 	defer ctrl.Finish()
 
 	mockUserRepo := repository.NewMockUserRepository(ctrl)
-	userService := NewUserService(mockUserRepo)
+	userService := NewUserService(mockUserRepo, publisher.NewNoopPublisher(), bcrypt.MinCost)
 
 	ctx := context.Background()
 
@@ -105,3 +110,502 @@ func TestUserService_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_CreateMany(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var published []*model.UserLogModel
+	events := publisher.NewMemoryPublisher()
+	events.Subscribe(func(ctx context.Context, userLog *model.UserLogModel) error {
+		published = append(published, userLog)
+		return nil
+	})
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, events, bcrypt.MinCost)
+
+	ctx := context.Background()
+	requests := []*dto.CreateUserRequest{
+		{Name: "John Doe", Email: "John@Doe.com", Password: "password"},
+		{Name: "Jane Doe", Email: "jane@doe.com", Password: "password"},
+		{Name: "Jim Doe", Email: "jim@doe.com", Password: "password"},
+	}
+
+	mockUserRepo.EXPECT().ExistingEmails(ctx, []string{"john@doe.com", "jane@doe.com", "jim@doe.com"}).Return([]string{"jane@doe.com"}, nil)
+	mockUserRepo.EXPECT().CreateMany(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, users []*model.UserModel) error {
+		if len(users) != 2 || users[0].Email != "john@doe.com" || users[1].Email != "jim@doe.com" {
+			t.Fatalf("expected John and Jim to be created, got %+v", users)
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(users[0].Password), []byte("password")); err != nil {
+			t.Errorf("expected the password to be hashed, got %v", err)
+		}
+		return nil
+	})
+
+	created, err := userService.CreateMany(ctx, requests)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if created != 2 {
+		t.Errorf("expected 2 users created, got %d", created)
+	}
+
+	if len(published) != 1 || published[0].Event != model.UserLogEventImport {
+		t.Fatalf("expected a single import event, got %+v", published)
+	}
+	if data, _ := published[0].Data.(map[string]interface{}); data["total"] != 3 || data["succeeded"] != 2 {
+		t.Errorf("expected 2 of 3 users in the import event, got %+v", published[0].Data)
+	}
+}
+
+func TestUserService_FindHighlightsSearch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, publisher.NewNoopPublisher(), bcrypt.MinCost)
+
+	ctx := context.Background()
+
+	tests := []struct {
+		name              string
+		user              *model.UserModel
+		search            string
+		expectedHighlight string
+	}{
+		{
+			name:              "substring in name is marked",
+			user:              &model.UserModel{Name: "John Doe", Email: "john@doe.com", SearchField: "name"},
+			search:            "doe",
+			expectedHighlight: "John <mark>Doe</mark>",
+		},
+		{
+			name:              "substring in email is marked",
+			user:              &model.UserModel{Name: "Jane", Email: "jane@example.com", SearchField: "email"},
+			search:            "example",
+			expectedHighlight: "jane@<mark>example</mark>.com",
+		},
+		{
+			name:              "fuzzy match marks whole field",
+			user:              &model.UserModel{Name: "Jonathan", Email: "jon@doe.com", SearchField: "name"},
+			search:            "jonatan",
+			expectedHighlight: "<mark>Jonathan</mark>",
+		},
+		{
+			name:              "value is html escaped",
+			user:              &model.UserModel{Name: "<b>Doe</b>", SearchField: "name"},
+			search:            "doe",
+			expectedHighlight: "&lt;b&gt;<mark>Doe</mark>&lt;/b&gt;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &dto.QueryUserRequest{Search: tt.search, Page: 1, PageSize: 10}
+			mockUserRepo.EXPECT().Find(ctx, request).Return([]*model.UserModel{tt.user}, int64(1), nil)
+
+			users, _, err := userService.Find(ctx, request)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if users[0].SearchHighlight != tt.expectedHighlight {
+				t.Errorf("expected highlight %q, got %q", tt.expectedHighlight, users[0].SearchHighlight)
+			}
+		})
+	}
+}
+
+func TestUserService_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, publisher.NewNoopPublisher(), bcrypt.MinCost)
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	tests := []struct {
+		name            string
+		version         int
+		setupMock       func()
+		expectedError   error
+		expectedVersion int
+	}{
+		{
+			name:    "update passes the expected version to the repository",
+			version: 3,
+			setupMock: func() {
+				mockUserRepo.EXPECT().Update(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, user *model.UserModel) error {
+					if user.Version != 3 {
+						t.Errorf("expected version 3, got %d", user.Version)
+					}
+					user.Version++
+					return nil
+				})
+			},
+			expectedVersion: 4,
+		},
+		{
+			name:    "update fails when the version is stale",
+			version: 1,
+			setupMock: func() {
+				mockUserRepo.EXPECT().Update(ctx, gomock.Any()).Return(portrepository.ErrVersionConflict)
+			},
+			expectedError: portrepository.ErrVersionConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			user, err := userService.Update(ctx, id, tt.version, &dto.UpdateUserRequest{Name: "John Doe", Email: "john@doe.com"})
+
+			if tt.expectedError != nil {
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected error %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if user.Version != tt.expectedVersion {
+				t.Errorf("expected version %d, got %d", tt.expectedVersion, user.Version)
+			}
+		})
+	}
+}
+
+func TestUserService_AdminUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, publisher.NewNoopPublisher(), bcrypt.MinCost)
+
+	ctx := context.Background()
+	id := uuid.New()
+
+	t.Run("reset password stores a hash", func(t *testing.T) {
+		mockUserRepo.EXPECT().UpdateColumnsBy(ctx, "id", id.String(), gomock.Any()).DoAndReturn(func(ctx context.Context, column, value string, values map[string]interface{}) error {
+			hash, _ := values["password"].(string)
+			if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("new-password")); err != nil {
+				t.Errorf("expected a hash of the new password, got %q", hash)
+			}
+			return nil
+		})
+
+		if err := userService.ResetPassword(ctx, id, "new-password"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("set role", func(t *testing.T) {
+		mockUserRepo.EXPECT().UpdateColumnsBy(ctx, "id", id.String(), map[string]interface{}{"role": model.UserRoleAdmin}).Return(nil)
+
+		if err := userService.SetRole(ctx, id, model.UserRoleAdmin); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("disable an unknown user", func(t *testing.T) {
+		mockUserRepo.EXPECT().UpdateColumnsBy(ctx, "id", id.String(), gomock.Any()).DoAndReturn(func(ctx context.Context, column, value string, values map[string]interface{}) error {
+			if _, ok := values["disabled_at"]; !ok {
+				t.Errorf("expected disabled_at to be set, got %v", values)
+			}
+			return portrepository.ErrNotFound
+		})
+
+		if err := userService.Disable(ctx, id); !errors.Is(err, portrepository.ErrNotFound) {
+			t.Errorf("expected not found, got %v", err)
+		}
+	})
+}
+
+func TestUserService_Import(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, publisher.NewNoopPublisher(), bcrypt.MinCost)
+
+	ctx := context.Background()
+
+	csvFile := "name,email,password\n" +
+		"John Doe,john@doe.com,password\n" +
+		"No Email,,password\n" +
+		"Jane Doe,jane@doe.com,short\n" +
+		"Taken,Taken@Doe.com,password\n" +
+		"John Again,JOHN@doe.com,password\n"
+
+	tests := []struct {
+		name              string
+		format            string
+		file              string
+		dryRun            bool
+		setupMock         func()
+		expectedSucceeded int
+		expectedErrors    map[int]string
+	}{
+		{
+			name:   "dry run validates without creating users",
+			format: dto.ImportFormatCSV,
+			file:   csvFile,
+			dryRun: true,
+			setupMock: func() {
+				mockUserRepo.EXPECT().ExistingEmails(ctx, []string{"john@doe.com", "taken@doe.com"}).Return([]string{"taken@doe.com"}, nil)
+			},
+			expectedSucceeded: 1,
+			expectedErrors:    map[int]string{2: "email", 3: "password", 4: "email", 5: "email"},
+		},
+		{
+			name:   "valid rows are created with hashed passwords",
+			format: dto.ImportFormatNDJSON,
+			file:   `{"name":"John Doe","email":"john@doe.com","password":"password"}` + "\n\n" + `{"name":"Jane Doe","email":"jane@doe.com","password":"password","role":"admin"}`,
+			setupMock: func() {
+				mockUserRepo.EXPECT().ExistingEmails(ctx, []string{"john@doe.com"}).Return(nil, nil)
+				mockUserRepo.EXPECT().CreateMany(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, users []*model.UserModel) error {
+					if len(users) != 1 {
+						t.Errorf("expected 1 user, got %d", len(users))
+					}
+
+					if err := bcrypt.CompareHashAndPassword([]byte(users[0].Password), []byte("password")); err != nil {
+						t.Errorf("Password hash verification failed: %v", err)
+					}
+					return nil
+				})
+			},
+			expectedSucceeded: 1,
+			expectedErrors:    map[int]string{2: "row"},
+		},
+		{
+			name:   "failed batch is retried row by row",
+			format: dto.ImportFormatNDJSON,
+			file:   `{"name":"John Doe","email":"john@doe.com","password":"password"}` + "\n" + `{"name":"Jane Doe","email":"jane@doe.com","password":"password"}`,
+			setupMock: func() {
+				mockUserRepo.EXPECT().ExistingEmails(ctx, gomock.Any()).Return(nil, nil)
+				mockUserRepo.EXPECT().CreateMany(ctx, gomock.Any()).Return(portrepository.ErrAlreadyExists)
+				mockUserRepo.EXPECT().Create(ctx, gomock.Any()).Return(nil)
+				mockUserRepo.EXPECT().Create(ctx, gomock.Any()).Return(portrepository.ErrAlreadyExists)
+			},
+			expectedSucceeded: 1,
+			expectedErrors:    map[int]string{2: "email"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			report, err := userService.Import(ctx, strings.NewReader(tt.file), "users."+tt.format, tt.format, tt.dryRun)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if report.Succeeded != tt.expectedSucceeded {
+				t.Errorf("expected %d succeeded rows, got %d", tt.expectedSucceeded, report.Succeeded)
+			}
+
+			if report.Failed != len(tt.expectedErrors) {
+				t.Errorf("expected %d failed rows, got %d", len(tt.expectedErrors), report.Failed)
+			}
+
+			for _, row := range report.Rows {
+				field, shouldFail := tt.expectedErrors[row.Row]
+				if row.Success == shouldFail {
+					t.Errorf("row %d: expected success=%v, got %v (%v)", row.Row, !shouldFail, row.Success, row.Errors)
+					continue
+				}
+
+				if shouldFail {
+					if _, ok := row.Errors[field]; !ok {
+						t.Errorf("row %d: expected an error on %s, got %v", row.Row, field, row.Errors)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestUserService_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, publisher.NewNoopPublisher(), bcrypt.MinCost)
+
+	ctx := context.Background()
+	users := []*model.UserModel{
+		{ID: uuid.MustParse("7b1c3e52-4a39-4a67-9e64-5f3c1b0c2a11"), Name: "John Doe", Email: "john@doe.com"},
+		{ID: uuid.MustParse("0f0b8e7c-2d3a-4c55-8f7e-1a2b3c4d5e6f"), Name: "Doe, Jane", Email: "jane@doe.com"},
+		{ID: uuid.MustParse("5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"), Name: "=1+2", Email: "@evil.com"},
+	}
+
+	tests := []struct {
+		name           string
+		request        *dto.ExportUserRequest
+		expectedOutput string
+	}{
+		{
+			name:    "csv with selected columns",
+			request: &dto.ExportUserRequest{Format: dto.ExportFormatCSV, SelectColumns: []string{"name", "email"}},
+			expectedOutput: "name,email\n" +
+				"John Doe,john@doe.com\n" +
+				"\"Doe, Jane\",jane@doe.com\n" +
+				"'=1+2,'@evil.com\n",
+		},
+		{
+			name:    "ndjson with selected columns",
+			request: &dto.ExportUserRequest{Format: dto.ExportFormatNDJSON, SelectColumns: []string{"id", "email"}},
+			expectedOutput: `{"email":"john@doe.com","id":"7b1c3e52-4a39-4a67-9e64-5f3c1b0c2a11"}` + "\n" +
+				`{"email":"jane@doe.com","id":"0f0b8e7c-2d3a-4c55-8f7e-1a2b3c4d5e6f"}` + "\n" +
+				`{"email":"@evil.com","id":"5d4c3b2a-1f0e-4d9c-8b7a-6f5e4d3c2b1a"}` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo.EXPECT().Stream(ctx, gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, request *dto.QueryUserRequest, fn func(*model.UserModel) error) error {
+				for _, user := range users {
+					if err := fn(user); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+
+			var output strings.Builder
+			count, err := userService.Export(ctx, tt.request, &output)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if count != len(users) {
+				t.Errorf("expected %d exported users, got %d", len(users), count)
+			}
+
+			if output.String() != tt.expectedOutput {
+				t.Errorf("expected output %q, got %q", tt.expectedOutput, output.String())
+			}
+		})
+	}
+}
+
+func TestUserService_UpgradePasswordHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, publisher.NewNoopPublisher(), bcrypt.MinCost+1)
+
+	ctx := context.Background()
+	cheap, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	current, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost+1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	t.Run("outdated hash is replaced", func(t *testing.T) {
+		user := &model.UserModel{ID: uuid.New(), Password: string(cheap)}
+		mockUserRepo.EXPECT().UpdateColumnsBy(ctx, "id", user.ID.String(), gomock.Any()).DoAndReturn(func(ctx context.Context, column, value string, columns map[string]interface{}) error {
+			hash, _ := columns["password"].(string)
+			if cost, err := bcrypt.Cost([]byte(hash)); err != nil || cost != bcrypt.MinCost+1 {
+				t.Errorf("expected a hash of cost %d, got %d (%v)", bcrypt.MinCost+1, cost, err)
+			}
+			if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte("password")); err != nil {
+				t.Errorf("expected the new hash to match the password, got %v", err)
+			}
+			return nil
+		})
+
+		if err := userService.UpgradePasswordHash(ctx, user, "password"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("current hash is kept", func(t *testing.T) {
+		user := &model.UserModel{ID: uuid.New(), Password: string(current)}
+		if err := userService.UpgradePasswordHash(ctx, user, "password"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("outdated hashes are counted", func(t *testing.T) {
+		mockUserRepo.EXPECT().Stream(ctx, &dto.QueryUserRequest{Deleted: dto.DeletedInclude}, gomock.Any()).DoAndReturn(func(ctx context.Context, request *dto.QueryUserRequest, fn func(*model.UserModel) error) error {
+			for _, hash := range [][]byte{cheap, current, cheap} {
+				if err := fn(&model.UserModel{ID: uuid.New(), Password: string(hash)}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+
+		checked, outdated, err := userService.CountOutdatedPasswordHashes(ctx)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if checked != 3 || outdated != 2 {
+			t.Errorf("expected 2 outdated hashes out of 3, got %d out of %d", outdated, checked)
+		}
+	})
+}
+
+func TestUserService_PublishesEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var published []*model.UserLogModel
+	events := publisher.NewMemoryPublisher()
+	events.Subscribe(func(ctx context.Context, userLog *model.UserLogModel) error {
+		published = append(published, userLog)
+		return nil
+	})
+
+	mockUserRepo := repository.NewMockUserRepository(ctrl)
+	userService := NewUserService(mockUserRepo, events, bcrypt.MinCost)
+
+	ctx := requestctx.WithRequestID(requestctx.WithActor(context.Background(), "admin-1"), "req-1")
+	id := uuid.New()
+
+	mockUserRepo.EXPECT().DeleteOneBy(ctx, "id", id.String()).Return(nil)
+	mockUserRepo.EXPECT().RestoreOneBy(ctx, "id", id.String()).Return(portrepository.ErrAlreadyExists)
+
+	if err := userService.DeleteOneByID(ctx, id); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := userService.RestoreOneByID(ctx, id); !errors.Is(err, portrepository.ErrAlreadyExists) {
+		t.Fatalf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	if len(published) != 1 {
+		t.Fatalf("expected only the successful change to be published, got %d events", len(published))
+	}
+
+	event := published[0]
+	if event.Event != model.UserLogEventDelete || event.UserID != "admin-1" || event.RequestID != "req-1" || event.CreatedAt.IsZero() {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	mockUserRepo.EXPECT().ExistingEmails(ctx, []string{"john@doe.com"}).Return(nil, nil)
+	mockUserRepo.EXPECT().CreateMany(ctx, gomock.Any()).Return(nil)
+
+	if _, err := userService.Import(ctx, strings.NewReader("name,email,password\nJohn Doe,john@doe.com,password\n"), "users.csv", dto.ImportFormatCSV, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(published) != 2 || published[1].Event != model.UserLogEventImport {
+		t.Fatalf("expected the import to be published, got %+v", published[1:])
+	}
+	if data, _ := published[1].Data.(map[string]interface{}); data["file"] != "users.csv" {
+		t.Errorf("expected the file name in the import event, got %v", published[1].Data)
+	}
+}
@@ -0,0 +1,338 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"codetest/internal/logger"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	portservice "codetest/internal/port/service"
+	"codetest/internal/requestctx"
+
+	"github.com/google/uuid"
+)
+
+// finishTimeout bounds the final write of a job, which happens on a fresh
+// context so it still goes through while the worker is shutting down.
+const finishTimeout = 10 * time.Second
+
+// artifactSweepInterval is how often files of finished jobs older than the
+// artifact retention are removed from the artifact directory.
+const artifactSweepInterval = time.Hour
+
+type JobWorker struct {
+	jobRepository     portrepository.JobRepository
+	processors        map[model.JobType]portservice.JobProcessor
+	concurrency       int
+	pollInterval      time.Duration
+	staleAfter        time.Duration
+	maxAttempts       int
+	artifactDir       string
+	artifactRetention time.Duration
+}
+
+func NewJobWorker(jobRepository portrepository.JobRepository, processors map[model.JobType]portservice.JobProcessor, concurrency int, pollInterval, staleAfter time.Duration, maxAttempts int, artifactDir string, artifactRetention time.Duration) *JobWorker {
+	return &JobWorker{
+		jobRepository:     jobRepository,
+		processors:        processors,
+		concurrency:       max(concurrency, 1),
+		pollInterval:      pollInterval,
+		staleAfter:        staleAfter,
+		maxAttempts:       max(maxAttempts, 1),
+		artifactDir:       artifactDir,
+		artifactRetention: artifactRetention,
+	}
+}
+
+// Run polls for queued jobs with the configured concurrency until ctx is
+// cancelled. It returns once every running job has stopped, jobs interrupted
+// by the shutdown are put back in the queue.
+func (w *JobWorker) Run(ctx context.Context) error {
+	slog.InfoContext(ctx, "Job worker started", slog.Int("workers", w.concurrency))
+
+	var wg sync.WaitGroup
+	for i := 0; i < w.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.poll(ctx)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.sweepArtifacts(ctx)
+	}()
+
+	wg.Wait()
+	slog.InfoContext(ctx, "Job worker stopped")
+	return nil
+}
+
+func (w *JobWorker) poll(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before waiting for the next tick.
+		for ctx.Err() == nil {
+			job, err := w.jobRepository.ClaimNext(ctx, time.Now().Add(-w.staleAfter))
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to claim job", logger.Error(err))
+				}
+				break
+			}
+
+			if job == nil {
+				break
+			}
+
+			w.process(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *JobWorker) process(ctx context.Context, job *model.JobModel) {
+	// Attempts only grows when a worker dies mid-job, a job that keeps taking
+	// its worker down is given up on instead of being reclaimed forever.
+	if job.Attempts > w.maxAttempts {
+		w.finish(job, model.JobStatusFailed, nil, fmt.Errorf("job abandoned after %d attempts", job.Attempts-1))
+		return
+	}
+
+	processor, ok := w.processors[job.Type]
+	if !ok {
+		w.finish(job, model.JobStatusFailed, nil, fmt.Errorf("unknown job type %q", job.Type))
+		return
+	}
+
+	// Changes made by the job are audited as its creator's.
+	ctx = requestctx.WithJobID(requestctx.WithActor(ctx, job.CreatedBy), job.ID.String())
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		progress  = model.JobModel{ID: job.ID, Progress: job.Progress, Total: job.Total}
+		cancelled atomic.Bool
+	)
+
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+
+		ticker := time.NewTicker(w.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-jobCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			mu.Lock()
+			snapshot := progress
+			mu.Unlock()
+
+			cancelRequested, err := w.jobRepository.Heartbeat(jobCtx, &snapshot)
+			if err != nil {
+				if jobCtx.Err() == nil {
+					slog.WarnContext(jobCtx, "Failed to heartbeat job", slog.String("job_id", job.ID.String()), logger.Error(err))
+				}
+				continue
+			}
+
+			if cancelRequested {
+				cancelled.Store(true)
+				cancel()
+				return
+			}
+		}
+	}()
+
+	result, err := w.run(jobCtx, processor, job, func(done, total int) {
+		mu.Lock()
+		progress.Progress, progress.Total = done, total
+		mu.Unlock()
+	})
+
+	cancel()
+	<-heartbeatDone
+
+	job.Progress, job.Total = progress.Progress, progress.Total
+
+	switch {
+	case cancelled.Load():
+		w.finish(job, model.JobStatusCancelled, nil, nil)
+	case ctx.Err() != nil:
+		w.requeue(job)
+	case err != nil:
+		w.finish(job, model.JobStatusFailed, nil, err)
+	default:
+		w.finish(job, model.JobStatusSucceeded, result, nil)
+	}
+}
+
+// run calls the processor, turning a panic into a failed job instead of
+// taking the whole server down.
+func (w *JobWorker) run(ctx context.Context, processor portservice.JobProcessor, job *model.JobModel, progress portservice.JobProgressFunc) (result interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return processor.Process(ctx, job, progress)
+}
+
+func (w *JobWorker) finish(job *model.JobModel, status model.JobStatus, result interface{}, jobErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	now := time.Now()
+	job.Status = status
+	job.FinishedAt = &now
+
+	if jobErr != nil {
+		job.Error = jobErr.Error()
+	}
+
+	if result != nil {
+		resultBytes, err := json.Marshal(result)
+		if err != nil {
+			job.Status = model.JobStatusFailed
+			job.Error = fmt.Sprintf("failed to encode result: %v", err)
+		} else {
+			job.Result = resultBytes
+		}
+	}
+
+	if job.Status != model.JobStatusSucceeded && job.ArtifactPath != "" {
+		_ = os.Remove(job.ArtifactPath)
+		job.ArtifactPath = ""
+	}
+
+	if job.InputPath != "" {
+		_ = os.Remove(job.InputPath)
+	}
+
+	if err := w.jobRepository.Finish(ctx, job); err != nil {
+		slog.ErrorContext(ctx, "Failed to finish job", slog.String("job_id", job.ID.String()), logger.Error(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Job finished", slog.String("job_id", job.ID.String()), slog.String("type", job.Type.String()), slog.String("status", job.Status.String()))
+}
+
+func (w *JobWorker) requeue(job *model.JobModel) {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if job.ArtifactPath != "" {
+		_ = os.Remove(job.ArtifactPath)
+	}
+
+	if err := w.jobRepository.Requeue(ctx, job); err != nil {
+		slog.ErrorContext(ctx, "Failed to requeue job", slog.String("job_id", job.ID.String()), logger.Error(err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Job requeued", slog.String("job_id", job.ID.String()), slog.String("type", job.Type.String()))
+}
+
+// sweepArtifacts removes files of the artifact directory older than the
+// retention, on start and then every artifactSweepInterval, until ctx is
+// cancelled.
+func (w *JobWorker) sweepArtifacts(ctx context.Context) {
+	ticker := time.NewTicker(artifactSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		removed, err := w.removeExpiredArtifacts(ctx, time.Now().Add(-w.artifactRetention))
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to sweep job artifacts", logger.Error(err))
+		} else if removed > 0 {
+			slog.InfoContext(ctx, "Swept job artifacts", slog.Int("removed", removed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// removeExpiredArtifacts removes the files modified before before. Files are
+// named after their job, the uploads of jobs still queued or running are kept
+// whatever their age.
+func (w *JobWorker) removeExpiredArtifacts(ctx context.Context, before time.Time) (int, error) {
+	entries, err := os.ReadDir(w.artifactDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+
+		if pending, err := w.jobPending(ctx, entry.Name()); err != nil || pending {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(w.artifactDir, entry.Name())); err == nil {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// jobPending reports whether the job an artifact file is named after has not
+// finished yet. Files of unknown or deleted jobs are not pending.
+func (w *JobWorker) jobPending(ctx context.Context, name string) (bool, error) {
+	id, _, _ := strings.Cut(name, ".")
+	if uuid.Validate(id) != nil {
+		return false, nil
+	}
+
+	job, err := w.jobRepository.GetOneBy(ctx, "id", id)
+	if err != nil {
+		if errors.Is(err, portrepository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return !job.Status.Finished(), nil
+}
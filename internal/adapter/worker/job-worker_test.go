@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"codetest/internal/adapter/repository/memory"
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+
+	"github.com/google/uuid"
+)
+
+func TestJobWorker_AbandonsJobAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewJobRepository()
+	w := NewJobWorker(repo, map[model.JobType]portservice.JobProcessor{}, 1, time.Second, time.Minute, 2, t.TempDir(), time.Hour)
+
+	job := &model.JobModel{Type: model.JobTypeUserExport}
+	if err := repo.Create(ctx, job); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Each claim with a stale cutoff in the future stands for a worker that
+	// died holding the job.
+	var claimed *model.JobModel
+	for i := 0; i < 3; i++ {
+		var err error
+		if claimed, err = repo.ClaimNext(ctx, time.Now().Add(time.Hour)); err != nil || claimed == nil {
+			t.Fatalf("expected the job to be claimed, got %+v, %v", claimed, err)
+		}
+	}
+
+	w.process(ctx, claimed)
+
+	got, err := repo.GetOneBy(ctx, "id", job.ID.String())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got.Status != model.JobStatusFailed || !strings.Contains(got.Error, "after 2 attempts") {
+		t.Errorf("expected the job to be abandoned, got %q %q", got.Status, got.Error)
+	}
+}
+
+func TestJobWorker_RemoveExpiredArtifacts(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	jobRepository := memory.NewJobRepository()
+	w := NewJobWorker(jobRepository, nil, 1, time.Second, time.Minute, 1, dir, time.Hour)
+
+	queued := &model.JobModel{ID: uuid.New(), Type: model.JobTypeUserImport, Status: model.JobStatusQueued}
+	finished := &model.JobModel{ID: uuid.New(), Type: model.JobTypeUserImport, Status: model.JobStatusSucceeded}
+	for _, job := range []*model.JobModel{queued, finished} {
+		if err := jobRepository.Create(ctx, job); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	expired := filepath.Join(dir, "expired.csv")
+	fresh := filepath.Join(dir, "fresh.csv")
+	pendingInput := filepath.Join(dir, queued.ID.String()+".input")
+	finishedInput := filepath.Join(dir, finished.ID.String()+".input")
+	old := time.Now().Add(-2 * time.Hour)
+	for _, path := range []string{expired, fresh, pendingInput, finishedInput} {
+		if err := os.WriteFile(path, []byte("id\n"), 0o640); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if path == fresh {
+			continue
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	removed, err := w.removeExpiredArtifacts(ctx, time.Now().Add(-w.artifactRetention))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("expected 2 artifacts removed, got %d", removed)
+	}
+	for _, path := range []string{expired, finishedInput} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got %v", filepath.Base(path), err)
+		}
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("expected the fresh artifact to be kept, got %v", err)
+	}
+	if _, err := os.Stat(pendingInput); err != nil {
+		t.Errorf("expected the input of a queued job to be kept, got %v", err)
+	}
+
+	w.artifactDir = filepath.Join(dir, "missing")
+	if _, err := w.removeExpiredArtifacts(ctx, time.Now()); err != nil {
+		t.Errorf("expected a missing directory to be ignored, got %v", err)
+	}
+}
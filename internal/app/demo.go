@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"codetest/internal/model"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	demoUserCount = 25
+	demoAdmin     = "admin@example.com"
+	demoPassword  = "password"
+)
+
+// seedDemo fills the in-memory repositories with an admin, sample users and
+// a few user logs so every endpoint has something to show.
+func (s *ServerApp) seedDemo(ctx context.Context) error {
+	passBytes, err := bcrypt.GenerateFromPassword([]byte(demoPassword), s.Cfg.PASSWORD_HASH_COST)
+	if err != nil {
+		return err
+	}
+
+	users := []*model.UserModel{{
+		Name:     "Demo Admin",
+		Email:    demoAdmin,
+		Password: string(passBytes),
+		Role:     model.UserRoleAdmin,
+	}}
+	for i := 1; i <= demoUserCount; i++ {
+		users = append(users, &model.UserModel{
+			Name:     fmt.Sprintf("User %d", i),
+			Email:    fmt.Sprintf("user%d@example.com", i),
+			Password: string(passBytes),
+		})
+	}
+
+	if err := s.userRepository.CreateMany(ctx, users); err != nil {
+		return err
+	}
+
+	admin := users[0].ID.String()
+	now := time.Now()
+	for i, user := range users[1:] {
+		if err := s.userLogRepository.Create(ctx, &model.UserLogModel{
+			UserID:    admin,
+			Event:     model.UserLogEventCreate,
+			Data:      map[string]interface{}{"id": user.ID, "email": user.Email},
+			CreatedAt: now.Add(time.Duration(i-len(users)) * time.Minute),
+		}); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Demo data seeded",
+		slog.Int("users", len(users)),
+		slog.String("admin", demoAdmin),
+		slog.String("password", demoPassword),
+	)
+	return nil
+}
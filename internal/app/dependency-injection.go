@@ -1,10 +1,27 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"strings"
+
 	"codetest/internal/adapter/api/handler"
+	"codetest/internal/adapter/api/middleware"
+	"codetest/internal/adapter/publisher"
+	"codetest/internal/adapter/repository/cache"
+	"codetest/internal/adapter/repository/fanout"
+	"codetest/internal/adapter/repository/file"
 	"codetest/internal/adapter/repository/gorm"
+	"codetest/internal/adapter/repository/memory"
 	"codetest/internal/adapter/repository/mongo"
 	"codetest/internal/adapter/service"
+	"codetest/internal/adapter/worker"
+	"codetest/internal/config"
+	"codetest/internal/model"
+	portrepository "codetest/internal/port/repository"
+	portservice "codetest/internal/port/service"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
 )
 
 func (s *ServerApp) dependencyInjections() error {
@@ -12,17 +29,131 @@ func (s *ServerApp) dependencyInjections() error {
 
 	s.jwtService = service.NewJWTService(s.Cfg)
 
-	s.userLogRepository = mongo.NewUserLogRepository(s.MongoDBConn.Client, "test", "user_logs")
+	window := s.Cfg.RATE_LIMIT_WINDOW
+	rateLimiter, err := middleware.NewRateLimiter(s.RedisConn.GetRedisInstance(), s.jwtService, map[string]middleware.RateLimit{
+		"auth":      {Limit: s.Cfg.RATE_LIMIT_AUTH, Window: window},
+		"users":     {Limit: s.Cfg.RATE_LIMIT_USERS, Window: window},
+		"user-logs": {Limit: s.Cfg.RATE_LIMIT_USER_LOGS, Window: window},
+	}, strings.Split(s.Cfg.RATE_LIMIT_ALLOWLIST, ","))
+	if err != nil {
+		return err
+	}
+	usersRoute := apiRoute.Group("", rateLimiter.For("users"))
+
+	s.userLogRepository, err = s.newUserLogRepository()
+	if err != nil {
+		return err
+	}
 	s.userLogService = service.NewUserLogService(s.userLogRepository)
 
-	s.userRepository = gorm.NewUserRepository(s.PostgresDBConn.GetDBInstance())
-	s.userService = service.NewUserService(s.userRepository)
-	s.userHandler = handler.NewUserHandler(apiRoute, s.userService, s.jwtService, s.RedisConn.GetRedisInstance(), s.Cfg.REDIS_USER_LOG_CHANNEL)
+	if s.demo {
+		s.userRepository = memory.NewUserRepository()
+		s.jobRepository = memory.NewJobRepository()
+	} else {
+		s.userRepository = gorm.NewUserRepository(s.DBConn.GetDBInstance())
+		s.jobRepository = gorm.NewJobRepository(s.DBConn.GetDBInstance())
+	}
+	if s.Cfg.USER_CACHE_ENABLED {
+		s.userRepository = cache.NewUserRepository(s.userRepository, s.RedisConn.GetRedisInstance(), s.Cfg.USER_CACHE_TTL)
+	}
+	s.eventPublisher = s.newEventPublisher()
+	s.userService = service.NewUserService(s.userRepository, s.eventPublisher, s.Cfg.PASSWORD_HASH_COST)
+
+	s.jobService = service.NewJobService(s.jobRepository, s.Cfg.JOB_ARTIFACT_DIR)
+	s.jobWorker = worker.NewJobWorker(s.jobRepository, map[model.JobType]portservice.JobProcessor{
+		model.JobTypeUserImport: service.NewUserImportJobProcessor(s.userService),
+		model.JobTypeUserExport: service.NewUserExportJobProcessor(s.userService, s.Cfg.JOB_ARTIFACT_DIR),
+		model.JobTypeUserPurge:  service.NewUserPurgeJobProcessor(s.userService),
+		model.JobTypeUserRehash: service.NewUserRehashJobProcessor(s.userService),
+	}, s.Cfg.JOB_WORKERS, s.Cfg.JOB_POLL_INTERVAL, s.Cfg.JOB_STALE_AFTER, s.Cfg.JOB_MAX_ATTEMPTS, s.Cfg.JOB_ARTIFACT_DIR, s.Cfg.JOB_ARTIFACT_RETENTION)
+
+	idempotency := middleware.IdempotencyMiddleware(s.RedisConn.GetRedisInstance(), s.Cfg.IDEMPOTENCY_TTL, s.Cfg.IDEMPOTENCY_MAX_BODY_SIZE)
+	s.userHandler = handler.NewUserHandler(usersRoute, s.userService, s.jobService, s.jwtService, s.eventPublisher, idempotency)
+	s.jobHandler = handler.NewJobHandler(usersRoute, s.jobService, s.userService, s.jwtService)
 
-	s.healthCheckHandler = handler.NewHealthCheckHandler(apiRoute)
+	s.healthService = service.NewHealthService(s.healthChecks(), s.Cfg.HEALTH_CHECK_TIMEOUT, s.Cfg.HEALTH_CACHE_TTL)
+	s.healthCheckHandler = handler.NewHealthCheckHandler(apiRoute, s.healthService)
 	s.swaggerHandler = handler.NewSwaggerHandler(apiRoute)
 
-	s.authHandler = handler.NewAuthHandler(apiRoute, s.userService, s.jwtService)
-	s.userLogHandler = handler.NewUserLogHandler(apiRoute, s.userLogService, s.jwtService)
+	s.authHandler = handler.NewAuthHandler(apiRoute.Group("", rateLimiter.For("auth")), s.userService, s.jwtService)
+	s.userLogHandler = handler.NewUserLogHandler(apiRoute.Group("", rateLimiter.For("user-logs")), s.userLogService, s.jwtService)
 	return nil
 }
+
+// newUserLogRepository writes user logs to every sink of USER_LOG_SINKS, or
+// keeps them in memory in demo mode.
+func (s *ServerApp) newUserLogRepository() (portrepository.UserLogRepository, error) {
+	if s.demo {
+		return memory.NewUserLogRepository(), nil
+	}
+
+	var repositories []portrepository.UserLogRepository
+	for _, sink := range s.Cfg.UserLogSinks() {
+		switch sink {
+		case config.UserLogSinkMongo:
+			repositories = append(repositories, mongo.NewUserLogRepository(s.MongoDBConn.Client, s.Cfg.MONGODB_DATABASE, s.Cfg.MONGODB_USER_LOG_COLLECTION))
+		case config.UserLogSinkDatabase:
+			repositories = append(repositories, gorm.NewUserLogRepository(s.DBConn.GetDBInstance()))
+		case config.UserLogSinkFile:
+			repository, err := file.NewUserLogRepository(s.Cfg.USER_LOG_FILE_PATH, int64(s.Cfg.USER_LOG_FILE_MAX_SIZE_MB)<<20, s.Cfg.USER_LOG_FILE_MAX_BACKUPS)
+			if err != nil {
+				return nil, err
+			}
+			repositories = append(repositories, repository)
+		}
+	}
+
+	if len(repositories) == 1 {
+		return repositories[0], nil
+	}
+	return fanout.NewUserLogRepository(repositories...), nil
+}
+
+// newEventPublisher returns the USER_LOG_PUBLISHER adapter. The memory one
+// stores events right away instead of going through Redis.
+func (s *ServerApp) newEventPublisher() portservice.EventPublisher {
+	switch s.Cfg.USER_LOG_PUBLISHER {
+	case config.UserLogPublisherMemory:
+		events := publisher.NewMemoryPublisher()
+		events.Subscribe(s.consumeUserLog)
+		return events
+	case config.UserLogPublisherNone:
+		return publisher.NewNoopPublisher()
+	default:
+		return publisher.NewRedisPublisher(s.RedisConn.GetRedisInstance(), s.Cfg.REDIS_USER_LOG_CHANNEL)
+	}
+}
+
+func (s *ServerApp) healthChecks() []service.HealthCheck {
+	var checks []service.HealthCheck
+	if s.DBConn != nil {
+		checks = append(checks, service.HealthCheck{Name: s.Cfg.DB_DRIVER, Check: func(ctx context.Context) error {
+			sqlDB, err := s.DBConn.GetDBInstance().DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		}})
+	}
+
+	checks = append(checks, service.HealthCheck{Name: "redis", Check: func(ctx context.Context) error {
+		return s.RedisConn.Ping(ctx).Err()
+	}})
+
+	if s.Cfg.USER_LOG_PUBLISHER == config.UserLogPublisherRedis {
+		checks = append(checks, service.HealthCheck{Name: "user_log_subscriber", Check: func(ctx context.Context) error {
+			if !s.subscriberRunning.Load() {
+				return errors.New("not subscribed to " + s.Cfg.REDIS_USER_LOG_CHANNEL)
+			}
+			return nil
+		}})
+	}
+
+	if s.MongoDBConn != nil {
+		checks = append(checks, service.HealthCheck{Name: "mongodb", Check: func(ctx context.Context) error {
+			return s.MongoDBConn.Ping(ctx, readpref.Primary())
+		}})
+	}
+
+	return checks
+}
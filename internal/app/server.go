@@ -3,86 +3,177 @@ package app
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"codetest/internal/adapter/api/handler"
+	"codetest/internal/adapter/api/middleware"
+	"codetest/internal/adapter/worker"
 	"codetest/internal/config"
+	"codetest/internal/logger"
+	"codetest/internal/metrics"
 	"codetest/internal/model"
+	"codetest/internal/persistent/database"
 	"codetest/internal/persistent/mongo"
-	"codetest/internal/persistent/postgres"
 	"codetest/internal/persistent/redis"
 	portrepository "codetest/internal/port/repository"
 	portservice "codetest/internal/port/service"
+	"codetest/internal/requestctx"
+	"codetest/internal/telemetry"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	goredis "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
 type ServerApp struct {
-	Router         *gin.Engine
-	Cfg            *config.AppConfig
-	PostgresDBConn *postgres.DBConnection
-	MongoDBConn    *mongo.DBConnection
-	RedisConn      *redis.RedisConnection
-
-	server *http.Server
-	quit   chan os.Signal
+	Router      *gin.Engine
+	Cfg         *config.AppConfig
+	DBConn      *database.DBConnection
+	MongoDBConn *mongo.DBConnection
+	RedisConn   *redis.RedisConnection
+
+	server            *http.Server
+	metricsServer     *http.Server
+	shutdownTelemetry func(context.Context) error
+	quit              chan os.Signal
+	subscriberRunning atomic.Bool
+	// demo keeps users, user logs and jobs in memory, see NewDemoServerApp.
+	demo bool
 
 	// Dependency injection
 	userService       portservice.UserService
 	userRepository    portrepository.UserRepository
 	jwtService        portservice.JWTService
+	eventPublisher    portservice.EventPublisher
 	userLogService    portservice.UserLogService
 	userLogRepository portrepository.UserLogRepository
+	jobRepository     portrepository.JobRepository
+	jobService        portservice.JobService
+	jobWorker         *worker.JobWorker
+	healthService     portservice.HealthService
 
 	swaggerHandler     *handler.SwaggerHandler
 	healthCheckHandler *handler.HealthCheckHandler
 	userHandler        *handler.UserHandler
 	authHandler        *handler.AuthHandler
 	userLogHandler     *handler.UserLogHandler
+	jobHandler         *handler.JobHandler
 }
 
 func NewServerApp(cfg *config.AppConfig) (*ServerApp, error) {
-	gin.SetMode(cfg.MODE)
+	s, err := newServerApp(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	engine := gin.New()
+	s.DBConn, err = database.NewDBConnection(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	server := &http.Server{
-		Addr:    ":" + cfg.PORT,
-		Handler: engine.Handler(),
+	if cfg.MIGRATE_ON_BOOT {
+		if err := s.DBConn.Migrate(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	if slices.Contains(cfg.UserLogSinks(), config.UserLogSinkMongo) {
+		s.MongoDBConn, err = mongo.NewMongoDBConnection(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	s.RedisConn, err = redis.NewRedisConnection(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	postgresDBInstance, err := postgres.NewPostgresDBConnection(cfg)
+	return s, nil
+}
+
+// NewDemoServerApp serves the full API without external services: users,
+// user logs and jobs live in memory, Redis is embedded, and sample data is
+// seeded on Run. Everything is lost on shutdown.
+func NewDemoServerApp(cfg *config.AppConfig) (*ServerApp, error) {
+	s, err := newServerApp(cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	mongoDBInstance, err := mongo.NewMongoDBConnection(cfg.MONGODB_URI)
+	s.RedisConn, err = redis.NewEmbeddedRedisConnection()
 	if err != nil {
 		return nil, err
 	}
 
-	redisInstance, err := redis.NewRedisConnection(cfg)
+	s.demo = true
+	return s, nil
+}
+
+// newServerApp sets up everything but the storage connections.
+func newServerApp(cfg *config.AppConfig) (*ServerApp, error) {
+	gin.SetMode(cfg.MODE)
+
+	// Tracing is set up first so the database clients are instrumented.
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName:    cfg.OTEL_SERVICE_NAME,
+		ServiceVersion: metrics.Version,
+		Exporter:       cfg.TRACING_EXPORTER,
+		FilePath:       cfg.TRACING_FILE,
+		SampleRatio:    cfg.TRACING_SAMPLE_RATIO,
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	engine := gin.New()
+	// Let handlers pass *gin.Context as a context.Context carrying the request
+	// span to services, repositories and clients.
+	engine.ContextWithFallback = true
+
+	// ClientIP only honours X-Forwarded-For from these proxies, which keeps
+	// the rate limiter keys and allowlist from being spoofed.
+	var trustedProxies []string
+	if cfg.TRUSTED_PROXIES != "" {
+		trustedProxies = strings.Split(cfg.TRUSTED_PROXIES, ",")
+	}
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{
+		Addr:    ":" + cfg.PORT,
+		Handler: engine.Handler(),
+	}
+
+	var metricsServer *http.Server
+	if cfg.METRICS_PORT != "" {
+		metricsServer = metrics.NewServer(":"+cfg.METRICS_PORT, cfg.METRICS_TOKEN)
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
 	return &ServerApp{
-		server:         server,
-		quit:           quit,
-		PostgresDBConn: postgresDBInstance,
-		MongoDBConn:    mongoDBInstance,
-		RedisConn:      redisInstance,
+		server:        server,
+		metricsServer: metricsServer,
+
+		shutdownTelemetry: shutdownTelemetry,
+		quit:              quit,
 
 		Router: engine,
 		Cfg:    cfg,
@@ -93,7 +184,15 @@ func (s *ServerApp) Run() {
 	s.middlewares()
 
 	if err := s.dependencyInjections(); err != nil {
-		log.Fatalf("Failed to inject dependencies: %v", err)
+		slog.Error("Failed to inject dependencies", logger.Error(err))
+		os.Exit(1)
+	}
+
+	if s.demo {
+		if err := s.seedDemo(context.Background()); err != nil {
+			slog.Error("Failed to seed demo data", logger.Error(err))
+			os.Exit(1)
+		}
 	}
 
 	if err := s.init(); err != nil {
@@ -105,7 +204,7 @@ func (s *ServerApp) init() error {
 	errg, errgCtx := errgroup.WithContext(context.Background())
 
 	errg.Go(func() error {
-		log.Printf("HTTP server listening on %s\n", s.server.Addr)
+		slog.Info("HTTP server listening", slog.String("addr", s.server.Addr))
 
 		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			return err
@@ -113,14 +212,46 @@ func (s *ServerApp) init() error {
 		return nil
 	})
 
+	if s.metricsServer != nil {
+		errg.Go(func() error {
+			slog.Info("Metrics server listening", slog.String("addr", s.metricsServer.Addr))
+
+			if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				return err
+			}
+			return nil
+		})
+	}
+
+	if s.Cfg.USER_LOG_PUBLISHER == config.UserLogPublisherRedis {
+		errg.Go(func() error {
+			s.subscribeToUserLogChannel(errgCtx)
+			return nil
+		})
+	}
+
+	// Jobs get their own context so the shutdown can stop them after the HTTP
+	// server drained and before the databases are closed.
+	workerCtx, cancelWorker := context.WithCancel(errgCtx)
+	defer cancelWorker()
+
+	workerDone := make(chan struct{})
 	errg.Go(func() error {
-		s.subscribeToUserLogChannel(errgCtx)
-		return nil
+		defer close(workerDone)
+		return s.jobWorker.Run(workerCtx)
 	})
 
 	errg.Go(func() error {
 		<-s.quit
-		log.Println("Shutting down server...")
+		slog.Info("Shutting down server")
+
+		// Fail readiness first so load balancers stop sending traffic before
+		// the listener goes away.
+		s.healthService.SetShuttingDown()
+		if delay := s.Cfg.SHUTDOWN_DELAY; delay > 0 {
+			slog.Info("Waiting before draining connections", slog.Duration("delay", delay))
+			time.Sleep(delay)
+		}
 
 		shutdownCtx, shutdownCancel := context.WithTimeout(errgCtx, 30*time.Second)
 		defer shutdownCancel()
@@ -129,19 +260,40 @@ func (s *ServerApp) init() error {
 			return err
 		}
 
-		if err := s.PostgresDBConn.Close(); err != nil {
-			return err
+		if s.metricsServer != nil {
+			if err := s.metricsServer.Shutdown(shutdownCtx); err != nil {
+				return err
+			}
 		}
 
-		if err := s.MongoDBConn.Close(shutdownCtx); err != nil {
-			return err
+		cancelWorker()
+		select {
+		case <-workerDone:
+		case <-shutdownCtx.Done():
+			return shutdownCtx.Err()
+		}
+
+		if s.DBConn != nil {
+			if err := s.DBConn.Close(); err != nil {
+				return err
+			}
+		}
+
+		if s.MongoDBConn != nil {
+			if err := s.MongoDBConn.Close(shutdownCtx); err != nil {
+				return err
+			}
 		}
 
 		if err := s.RedisConn.Close(); err != nil {
 			return err
 		}
 
-		log.Println("Server gracefully stopped")
+		if err := s.shutdownTelemetry(shutdownCtx); err != nil {
+			slog.Error("Failed to flush traces", logger.Error(err))
+		}
+
+		slog.Info("Server gracefully stopped")
 		return nil
 	})
 
@@ -149,8 +301,12 @@ func (s *ServerApp) init() error {
 }
 
 func (s *ServerApp) middlewares() {
-	s.Router.Use(gin.Recovery())
-	s.Router.Use(gin.Logger())
+	s.Router.Use(otelgin.Middleware(s.Cfg.OTEL_SERVICE_NAME))
+	s.Router.Use(middleware.RequestIDMiddleware())
+	s.Router.Use(middleware.QueryTimeoutMiddleware(s.Cfg.DB_QUERY_TIMEOUT))
+	s.Router.Use(middleware.LoggerMiddleware())
+	s.Router.Use(middleware.RecoveryMiddleware())
+	s.Router.Use(metrics.Middleware())
 
 	corsConfig := cors.Config{
 		AllowOrigins:     strings.Split(s.Cfg.CORS_ALLOWED_ORIGINS, ","),
@@ -166,33 +322,69 @@ func (s *ServerApp) middlewares() {
 func (s *ServerApp) subscribeToUserLogChannel(ctx context.Context) {
 	subscriber := s.RedisConn.Subscribe(ctx, s.Cfg.REDIS_USER_LOG_CHANNEL)
 	defer func() {
+		s.subscriberRunning.Store(false)
 		_ = subscriber.Close()
-		log.Println("Redis subscriber closed")
+		slog.Info("Redis subscriber closed")
 	}()
 
-	ch := subscriber.Channel()
+	// Subscription confirmations, including those after a reconnect, arrive
+	// on the same channel and mark the consumer ready.
+	ch := subscriber.ChannelWithSubscriptions()
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Redis subscription context cancelled")
+			slog.Info("Redis subscription context cancelled")
 			return
 		case msg, ok := <-ch:
 			if !ok {
-				log.Println("Redis channel closed")
+				slog.Warn("Redis channel closed")
 				return
 			}
 
-			var userLogData model.UserLogModel
-			if err := json.Unmarshal([]byte(msg.Payload), &userLogData); err != nil {
-				log.Printf("Failed to unmarshal user log data: %v", err)
-				continue
-			}
-
-			if err := s.userLogService.Create(ctx, &userLogData); err != nil {
-				log.Printf("Failed to save user log data: %v", err)
-				continue
+			switch msg := msg.(type) {
+			case *goredis.Subscription:
+				s.subscriberRunning.Store(msg.Kind == "subscribe")
+			case *goredis.Message:
+				s.consumeUserLogMessage(ctx, msg.Payload)
 			}
 		}
 	}
 }
+
+// consumeUserLogMessage decodes and stores one event of the Redis channel.
+func (s *ServerApp) consumeUserLogMessage(ctx context.Context, payload string) {
+	var userLogData model.UserLogModel
+	if err := json.Unmarshal([]byte(payload), &userLogData); err != nil {
+		slog.ErrorContext(ctx, "Failed to unmarshal user log data", logger.Error(err))
+		return
+	}
+
+	if err := s.consumeUserLog(ctx, &userLogData); err != nil {
+		slog.ErrorContext(ctx, "Failed to save user log data", logger.Error(err))
+	}
+}
+
+// consumeUserLog stores one user log event in a span continuing the trace of
+// the request that published it.
+func (s *ServerApp) consumeUserLog(ctx context.Context, userLog *model.UserLogModel) error {
+	metrics.UserLogConsumerLag.Observe(time.Since(userLog.CreatedAt).Seconds())
+
+	if userLog.RequestID != "" {
+		ctx = requestctx.WithRequestID(ctx, userLog.RequestID)
+	}
+
+	ctx, span := telemetry.Tracer().Start(telemetry.Extract(ctx, userLog.TraceContext), "user_log.consume",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(attribute.String("user_log.event", userLog.Event.String())),
+	)
+	defer span.End()
+
+	if err := s.userLogService.Create(ctx, userLog); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,628 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/adapter/api/presenter"
+	"codetest/internal/config"
+	"codetest/internal/model"
+	"codetest/internal/persistent/database"
+	"codetest/internal/persistent/redis"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// testServer drives a ServerApp through its router. Redis is embedded and
+// users, jobs and user logs live in SQLite or in the in-memory repositories,
+// which also stand in for the MongoDB sink, so the suite runs offline.
+type testServer struct {
+	app *ServerApp
+}
+
+type envelope struct {
+	Success    bool                 `json:"success"`
+	Data       json.RawMessage      `json:"data"`
+	Error      json.RawMessage      `json:"error"`
+	Message    string               `json:"message"`
+	Pagination presenter.Pagination `json:"pagination"`
+}
+
+func newTestServer(t *testing.T, backend string) *testServer {
+	t.Helper()
+
+	dir := t.TempDir()
+	for key, value := range map[string]string{
+		"CONFIG_FILE":          "",
+		"MODE":                 "test",
+		"METRICS_PORT":         "",
+		"TRACING_EXPORTER":     "none",
+		"DB_DRIVER":            database.DriverSQLite,
+		"SQLITE_PATH":          filepath.Join(dir, "codetest.db"),
+		"USER_LOG_SINKS":       config.UserLogSinkDatabase,
+		"USER_LOG_PUBLISHER":   config.UserLogPublisherRedis,
+		"USER_CACHE_ENABLED":   "false",
+		"JOB_POLL_INTERVAL":    "20ms",
+		"JOB_ARTIFACT_DIR":     filepath.Join(dir, "jobs"),
+		"CORS_ALLOWED_ORIGINS": "http://localhost",
+		"RATE_LIMIT_ALLOWLIST": "",
+	} {
+		t.Setenv(key, value)
+	}
+
+	cfg, err := config.NewAppConfig()
+	if err != nil {
+		t.Fatalf("expected a valid configuration, got %v", err)
+	}
+
+	s, err := newServerApp(cfg)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	switch backend {
+	case "memory":
+		s.demo = true
+	case database.DriverSQLite:
+		s.DBConn, err = database.NewDBConnection(cfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if err := s.DBConn.Migrate(context.Background()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	s.RedisConn, err = redis.NewEmbeddedRedisConnection()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	s.middlewares()
+	if err := s.dependencyInjections(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := s.seedDemo(context.Background()); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.subscribeToUserLogChannel(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		_ = s.jobWorker.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+		signal.Stop(s.quit)
+
+		if s.DBConn != nil {
+			if err := s.DBConn.Close(); err != nil {
+				t.Errorf("expected no error closing the database, got %v", err)
+			}
+		}
+		if err := s.RedisConn.Close(); err != nil {
+			t.Errorf("expected no error closing redis, got %v", err)
+		}
+		_ = s.shutdownTelemetry(context.Background())
+	})
+
+	eventually(t, "the user log subscriber to start", s.subscriberRunning.Load)
+
+	return &testServer{app: s}
+}
+
+// eventually polls condition until it holds or a few seconds went by.
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (ts *testServer) do(method, target, token string, body io.Reader, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, body)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	rec := httptest.NewRecorder()
+	ts.app.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func (ts *testServer) json(t *testing.T, method, target, token string, payload interface{}, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if header == nil {
+		header = http.Header{}
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/json")
+	}
+
+	return ts.do(method, target, token, bytes.NewReader(body), header)
+}
+
+// expect checks the status of rec and decodes its envelope, and its data
+// into data unless data is nil.
+func (ts *testServer) expect(t *testing.T, rec *httptest.ResponseRecorder, status int, data interface{}) envelope {
+	t.Helper()
+
+	if rec.Code != status {
+		t.Fatalf("expected status %d, got %d: %s", status, rec.Code, rec.Body.String())
+	}
+
+	var response envelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("expected a JSON envelope, got %q", rec.Body.String())
+	}
+
+	if response.Success != (status < 300) {
+		t.Errorf("expected success to be %v, got %s", status < 300, rec.Body.String())
+	}
+
+	if data != nil {
+		if err := json.Unmarshal(response.Data, data); err != nil {
+			t.Fatalf("expected data to decode, got %v: %s", err, response.Data)
+		}
+	}
+
+	return response
+}
+
+func (ts *testServer) login(t *testing.T, email, password string) dto.LoginResponse {
+	t.Helper()
+
+	var tokens dto.LoginResponse
+	ts.expect(t, ts.json(t, http.MethodPost, "/api/auth/login", "", dto.LoginRequest{Email: email, Password: password}, nil), 200, &tokens)
+	return tokens
+}
+
+func (ts *testServer) userByEmail(t *testing.T, token, email string) *model.UserModel {
+	t.Helper()
+
+	var users []*model.UserModel
+	response := ts.expect(t, ts.do(http.MethodGet, "/api/users?deleted=include&filter[email][eq]="+url.QueryEscape(email), token, nil, nil), 200, &users)
+	if len(users) != 1 || response.Pagination.TotalCount != 1 {
+		t.Fatalf("expected one user with email %s, got %d", email, len(users))
+	}
+	return users[0]
+}
+
+// waitForJob polls the job at location until it finished.
+func (ts *testServer) waitForJob(t *testing.T, token, location string) *model.JobModel {
+	t.Helper()
+
+	job := &model.JobModel{}
+	eventually(t, "job "+location, func() bool {
+		ts.expect(t, ts.do(http.MethodGet, location, token, nil, nil), 200, job)
+		return job.Status.Finished()
+	})
+	return job
+}
+
+func importForm(t *testing.T, fileName, content string, fields map[string]string) (io.Reader, http.Header) {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if _, err := io.WriteString(part, content); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return &body, http.Header{"Content-Type": {writer.FormDataContentType()}}
+}
+
+func TestServer(t *testing.T) {
+	for _, backend := range []string{database.DriverSQLite, "memory"} {
+		t.Run(backend, func(t *testing.T) {
+			testServerRoutes(t, newTestServer(t, backend))
+		})
+	}
+}
+
+func testServerRoutes(t *testing.T, ts *testServer) {
+	var admin, jane *model.UserModel
+	var adminToken, userToken string
+
+	// Every step builds on the previous ones.
+	step := func(name string, fn func(t *testing.T)) {
+		if !t.Failed() {
+			t.Run(name, fn)
+		}
+	}
+
+	step("health", func(t *testing.T) {
+		ts.expect(t, ts.do(http.MethodGet, "/api/health", "", nil, nil), 200, nil)
+		ts.expect(t, ts.do(http.MethodGet, "/api/health/live", "", nil, nil), 200, nil)
+
+		var report dto.HealthReport
+		ts.expect(t, ts.do(http.MethodGet, "/api/health/ready", "", nil, nil), 200, &report)
+		if report.Status != dto.HealthStatusUp {
+			t.Errorf("expected status up, got %s", report.Status)
+		}
+		for _, component := range []string{"redis", "user_log_subscriber"} {
+			if report.Components[component].Status != dto.HealthStatusUp {
+				t.Errorf("expected %s to be up, got %+v", component, report.Components)
+			}
+		}
+	})
+
+	step("swagger", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/swagger/doc.json", "", nil, nil)
+		if rec.Code != 200 || !strings.Contains(rec.Body.String(), `"/users"`) {
+			t.Errorf("expected the OpenAPI document, got %d", rec.Code)
+		}
+	})
+
+	step("auth failures", func(t *testing.T) {
+		ts.expect(t, ts.json(t, http.MethodPost, "/api/auth/login", "", dto.LoginRequest{Email: demoAdmin, Password: "wrong-password"}, nil), 400, nil)
+		ts.expect(t, ts.json(t, http.MethodPost, "/api/auth/login", "", map[string]string{"email": "not-an-email"}, nil), 400, nil)
+
+		for _, target := range []string{"/api/auth/me", "/api/users", "/api/user-logs", "/api/jobs/" + "00000000-0000-0000-0000-000000000000"} {
+			ts.expect(t, ts.do(http.MethodGet, target, "", nil, nil), 401, nil)
+			ts.expect(t, ts.do(http.MethodGet, target, "not-a-token", nil, nil), 401, nil)
+		}
+
+		ts.expect(t, ts.do(http.MethodPost, "/api/auth/refresh-token", "not-a-token", nil, nil), 401, nil)
+
+		// Tokens signed with the right keys but not naming a user ID.
+		claims := jwt.MapClaims{"user_id": "not-a-uuid", "exp": time.Now().Add(time.Minute).Unix()}
+		accessToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(ts.app.Cfg.ACCESS_TOKEN_KEY))
+		refreshToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(ts.app.Cfg.REFRESH_TOKEN_KEY))
+		ts.expect(t, ts.do(http.MethodGet, "/api/auth/me", accessToken, nil, nil), 401, nil)
+		ts.expect(t, ts.do(http.MethodPost, "/api/auth/refresh-token", refreshToken, nil, nil), 401, nil)
+	})
+
+	step("login", func(t *testing.T) {
+		tokens := ts.login(t, demoAdmin, demoPassword)
+		if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+			t.Fatalf("expected both tokens, got %+v", tokens)
+		}
+
+		// The refresh token is signed with another key.
+		ts.expect(t, ts.do(http.MethodGet, "/api/auth/me", tokens.RefreshToken, nil, nil), 401, nil)
+		ts.expect(t, ts.do(http.MethodPost, "/api/auth/refresh-token", tokens.AccessToken, nil, nil), 401, nil)
+
+		var refreshed dto.LoginResponse
+		ts.expect(t, ts.do(http.MethodPost, "/api/auth/refresh-token", tokens.RefreshToken, nil, nil), 200, &refreshed)
+		adminToken = refreshed.AccessToken
+
+		admin = &model.UserModel{}
+		ts.expect(t, ts.do(http.MethodGet, "/api/auth/me", adminToken, nil, nil), 200, admin)
+		if admin.Email != demoAdmin || admin.Role != model.UserRoleAdmin {
+			t.Errorf("expected the demo admin, got %+v", admin)
+		}
+	})
+
+	step("create user", func(t *testing.T) {
+		request := dto.CreateUserRequest{Name: "Jane Doe", Email: "jane@example.com", Password: "secret123", ConfirmPassword: "secret123"}
+		header := http.Header{"Idempotency-Key": {"create-jane"}}
+
+		response := ts.expect(t, ts.json(t, http.MethodPost, "/api/users", adminToken, request, header), 200, nil)
+		if response.Message != "User created successfully" {
+			t.Errorf("unexpected message %q", response.Message)
+		}
+
+		rec := ts.json(t, http.MethodPost, "/api/users", adminToken, request, http.Header{"Idempotency-Key": {"create-jane"}})
+		ts.expect(t, rec, 200, nil)
+		if rec.Header().Get("Idempotent-Replayed") != "true" {
+			t.Errorf("expected the retry to be replayed, got headers %v", rec.Header())
+		}
+
+		ts.expect(t, ts.json(t, http.MethodPost, "/api/users", adminToken, request, nil), 422, nil)
+
+		response = ts.expect(t, ts.json(t, http.MethodPost, "/api/users", adminToken, dto.CreateUserRequest{Name: "No Email", Password: "secret123"}, nil), 400, nil)
+		var problems map[string]string
+		if err := json.Unmarshal(response.Error, &problems); err != nil || problems["email"] == "" {
+			t.Errorf("expected an email validation error, got %s", response.Error)
+		}
+
+		jane = ts.userByEmail(t, adminToken, "jane@example.com")
+		if jane.Name != "Jane Doe" || jane.Role != model.UserRoleUser || jane.Version != 1 {
+			t.Errorf("unexpected user %+v", jane)
+		}
+
+		userToken = ts.login(t, "jane@example.com", "secret123").AccessToken
+	})
+
+	step("list users", func(t *testing.T) {
+		var users []*model.UserModel
+		response := ts.expect(t, ts.do(http.MethodGet, "/api/users?page=2&page_size=10&sort=email", adminToken, nil, nil), 200, &users)
+
+		// The demo admin, the demo users and Jane.
+		want := presenter.Pagination{Page: 2, PageSize: 10, TotalCount: demoUserCount + 2, TotalPages: 3}
+		if response.Pagination != want {
+			t.Errorf("expected pagination %+v, got %+v", want, response.Pagination)
+		}
+		if len(users) != 10 {
+			t.Errorf("expected 10 users, got %d", len(users))
+		}
+		for i := 1; i < len(users); i++ {
+			if users[i-1].Email > users[i].Email {
+				t.Errorf("expected users sorted by email, got %s before %s", users[i-1].Email, users[i].Email)
+			}
+		}
+
+		ts.expect(t, ts.do(http.MethodGet, "/api/users?filter[password][eq]=x", adminToken, nil, nil), 400, nil)
+		ts.expect(t, ts.do(http.MethodGet, "/api/users?page_size=1000", adminToken, nil, nil), 400, nil)
+	})
+
+	step("get user", func(t *testing.T) {
+		user := &model.UserModel{}
+		rec := ts.do(http.MethodGet, "/api/users/"+jane.ID.String(), userToken, nil, nil)
+		ts.expect(t, rec, 200, user)
+		if user.ID != jane.ID || user.Email != jane.Email {
+			t.Errorf("expected %s, got %+v", jane.ID, user)
+		}
+		if etag := rec.Header().Get("ETag"); etag != `"1"` {
+			t.Errorf(`expected ETag "1", got %q`, etag)
+		}
+
+		ts.expect(t, ts.do(http.MethodGet, "/api/users/not-a-uuid", userToken, nil, nil), 400, nil)
+		ts.expect(t, ts.do(http.MethodGet, "/api/users/"+uuid.NewString(), userToken, nil, nil), 404, nil)
+
+		target := "/api/user-logs?sort=-created_at&filter[event][eq]=" + url.QueryEscape(model.UserLogEventRead.String()) + "&filter[user_id][eq]=" + jane.ID.String()
+		var logs []*model.UserLogModel
+		eventually(t, "read audit of "+jane.ID.String(), func() bool {
+			ts.expect(t, ts.do(http.MethodGet, target, userToken, nil, nil), 200, &logs)
+			return len(logs) > 0
+		})
+		if data, _ := logs[0].Data.(map[string]interface{}); data["id"] != jane.ID.String() || data["email"] != jane.Email || data["name"] != jane.Name {
+			t.Errorf("expected the read user in the audit event, got %+v", logs[0].Data)
+		}
+	})
+
+	step("update user", func(t *testing.T) {
+		path := "/api/users/" + jane.ID.String()
+		request := dto.UpdateUserRequest{Name: "Jane Smith", Email: "jane@example.com"}
+
+		ts.expect(t, ts.json(t, http.MethodPut, path, adminToken, request, nil), 428, nil)
+		ts.expect(t, ts.json(t, http.MethodPut, path, adminToken, request, http.Header{"If-Match": {"1"}}), 400, nil)
+		ts.expect(t, ts.json(t, http.MethodPut, path, adminToken, request, http.Header{"If-Match": {`W/"1"`}}), 412, nil)
+		ts.expect(t, ts.json(t, http.MethodPut, "/api/users/"+uuid.NewString(), adminToken, request, http.Header{"If-Match": {"*"}}), 412, nil)
+
+		rec := ts.json(t, http.MethodPut, path, adminToken, request, http.Header{"If-Match": {`"1"`}})
+		ts.expect(t, rec, 200, nil)
+		if etag := rec.Header().Get("ETag"); etag != `"2"` {
+			t.Errorf(`expected ETag "2", got %q`, etag)
+		}
+
+		ts.expect(t, ts.json(t, http.MethodPut, path, adminToken, request, http.Header{"If-Match": {`"1"`}}), 412, nil)
+		ts.expect(t, ts.json(t, http.MethodPut, path, adminToken, dto.UpdateUserRequest{Name: "Jane Smith", Email: demoAdmin}, http.Header{"If-Match": {`"2"`}}), 422, nil)
+	})
+
+	step("patch user", func(t *testing.T) {
+		path := "/api/users/" + jane.ID.String()
+
+		ts.expect(t, ts.do(http.MethodPatch, path, adminToken, strings.NewReader(`{"name":"Jane Patched"}`), http.Header{"Content-Type": {"text/plain"}, "If-Match": {`"2"`}}), 415, nil)
+		ts.expect(t, ts.do(http.MethodPatch, path, adminToken, strings.NewReader(`{"role":"admin"}`), http.Header{"Content-Type": {"application/merge-patch+json"}, "If-Match": {`"2"`}}), 422, nil)
+
+		user := &model.UserModel{}
+		rec := ts.do(http.MethodPatch, path, adminToken, strings.NewReader(`{"name":"Jane Patched"}`), http.Header{"Content-Type": {"application/merge-patch+json"}, "If-Match": {`"2"`}})
+		ts.expect(t, rec, 200, user)
+		if user.Name != "Jane Patched" || user.Version != 3 {
+			t.Errorf("expected the patched user at version 3, got %+v", user)
+		}
+		if etag := rec.Header().Get("ETag"); etag != `"3"` {
+			t.Errorf(`expected ETag "3", got %q`, etag)
+		}
+
+		rec = ts.do(http.MethodPatch, path, adminToken, strings.NewReader(`{"name":"Jane Patched"}`), http.Header{"Content-Type": {"application/merge-patch+json"}, "If-Match": {"*"}})
+		ts.expect(t, rec, 200, nil)
+		if etag := rec.Header().Get("ETag"); etag != `"4"` {
+			t.Errorf(`expected "*" to match any version, got ETag %q`, etag)
+		}
+	})
+
+	step("delete and restore user", func(t *testing.T) {
+		path := "/api/users/" + jane.ID.String()
+
+		ts.expect(t, ts.do(http.MethodPost, path+"/restore", adminToken, nil, nil), 404, nil)
+		ts.expect(t, ts.do(http.MethodDelete, path+"?hard=true", userToken, nil, nil), 403, nil)
+
+		ts.expect(t, ts.do(http.MethodDelete, path, adminToken, nil, nil), 200, nil)
+		ts.expect(t, ts.do(http.MethodDelete, path, adminToken, nil, nil), 404, nil)
+
+		var users []*model.UserModel
+		ts.expect(t, ts.do(http.MethodGet, "/api/users?deleted=only", adminToken, nil, nil), 200, &users)
+		if len(users) != 1 || users[0].ID != jane.ID {
+			t.Errorf("expected only Jane to be deleted, got %d users", len(users))
+		}
+
+		ts.expect(t, ts.do(http.MethodPost, path+"/restore", adminToken, nil, nil), 200, nil)
+		ts.userByEmail(t, adminToken, "jane@example.com")
+		unknown := "/api/users/" + uuid.NewString()
+		ts.expect(t, ts.do(http.MethodDelete, unknown, adminToken, nil, nil), 404, nil)
+		ts.expect(t, ts.do(http.MethodPost, unknown+"/restore", adminToken, nil, nil), 404, nil)
+		ts.expect(t, ts.do(http.MethodPatch, unknown, adminToken, strings.NewReader(`{"name":"Nobody"}`), http.Header{"Content-Type": {"application/merge-patch+json"}, "If-Match": {`"1"`}}), 404, nil)
+	})
+
+	step("import users", func(t *testing.T) {
+		csv := "name,email,password\nAlice,alice@example.com,secret123\nDuplicate,jane@example.com,secret123\n"
+
+		body, header := importForm(t, "users.csv", csv, nil)
+		var report dto.ImportUserReport
+		ts.expect(t, ts.do(http.MethodPost, "/api/users/import", adminToken, body, header), 200, &report)
+		if report.Total != 2 || report.Succeeded != 1 || report.Failed != 1 {
+			t.Errorf("expected one imported and one failed row, got %+v", report)
+		}
+		ts.userByEmail(t, adminToken, "alice@example.com")
+
+		body, header = importForm(t, "users.txt", csv, nil)
+		ts.expect(t, ts.do(http.MethodPost, "/api/users/import", adminToken, body, header), 400, nil)
+
+		body, header = importForm(t, "users.ndjson", `{"name":"Bob","email":"bob@example.com","password":"secret123"}`+"\n", map[string]string{"async": "true"})
+		rec := ts.do(http.MethodPost, "/api/users/import", userToken, body, header)
+		ts.expect(t, rec, 202, nil)
+
+		job := ts.waitForJob(t, userToken, rec.Header().Get("Location"))
+		if job.Status != model.JobStatusSucceeded || job.Type != model.JobTypeUserImport {
+			t.Fatalf("expected the import to succeed, got %+v", job)
+		}
+		ts.userByEmail(t, adminToken, "bob@example.com")
+	})
+
+	step("export users", func(t *testing.T) {
+		rec := ts.do(http.MethodGet, "/api/users/export?columns=email,name&sort=email&filter[email][ilike]=jane", adminToken, nil, nil)
+		if rec.Code != 200 {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if contentType := rec.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+			t.Errorf("expected a CSV, got %q", contentType)
+		}
+		if want := "email,name\njane@example.com,Jane Patched\n"; rec.Body.String() != want {
+			t.Errorf("expected %q, got %q", want, rec.Body.String())
+		}
+
+		ts.expect(t, ts.do(http.MethodGet, "/api/users/export?columns=password", adminToken, nil, nil), 400, nil)
+
+		rec = ts.do(http.MethodGet, "/api/users/export?async=true&format=ndjson&columns=email&filter[email][eq]=alice@example.com", adminToken, nil, nil)
+		ts.expect(t, rec, 202, nil)
+		location := rec.Header().Get("Location")
+
+		job := ts.waitForJob(t, adminToken, location)
+		if job.Status != model.JobStatusSucceeded || !job.HasArtifact {
+			t.Fatalf("expected the export to succeed with an artifact, got %+v", job)
+		}
+
+		rec = ts.do(http.MethodGet, location+"/artifact", adminToken, nil, nil)
+		if rec.Code != 200 || rec.Body.String() != `{"email":"alice@example.com"}`+"\n" {
+			t.Errorf("expected the exported row, got %d: %q", rec.Code, rec.Body.String())
+		}
+
+		ts.expect(t, ts.do(http.MethodPost, location+"/cancel", adminToken, nil, nil), 409, nil)
+
+		// Jobs of other users look like they do not exist.
+		ts.expect(t, ts.do(http.MethodGet, location, userToken, nil, nil), 404, nil)
+		ts.expect(t, ts.do(http.MethodGet, location+"/artifact", userToken, nil, nil), 404, nil)
+		ts.expect(t, ts.do(http.MethodGet, "/api/jobs/00000000-0000-0000-0000-000000000000", adminToken, nil, nil), 404, nil)
+	})
+
+	step("purge users", func(t *testing.T) {
+		request := dto.PurgeUsersRequest{OlderThanDays: 30}
+
+		ts.expect(t, ts.json(t, http.MethodPost, "/api/users/purge", userToken, request, nil), 403, nil)
+		ts.expect(t, ts.json(t, http.MethodPost, "/api/users/purge", adminToken, dto.PurgeUsersRequest{}, nil), 400, nil)
+
+		rec := ts.json(t, http.MethodPost, "/api/users/purge", adminToken, request, nil)
+		ts.expect(t, rec, 202, nil)
+
+		job := ts.waitForJob(t, adminToken, rec.Header().Get("Location"))
+		if job.Status != model.JobStatusSucceeded {
+			t.Fatalf("expected the purge to succeed, got %+v", job)
+		}
+
+		bob := ts.userByEmail(t, adminToken, "bob@example.com")
+		ts.expect(t, ts.do(http.MethodDelete, "/api/users/"+bob.ID.String()+"?hard=true", adminToken, nil, nil), 200, nil)
+
+		var users []*model.UserModel
+		ts.expect(t, ts.do(http.MethodGet, "/api/users?deleted=include&filter[email][eq]=bob@example.com", adminToken, nil, nil), 200, &users)
+		if len(users) != 0 {
+			t.Errorf("expected Bob to be purged, got %+v", users)
+		}
+	})
+
+	step("check password hashes", func(t *testing.T) {
+		ts.expect(t, ts.do(http.MethodPost, "/api/users/rehash", userToken, nil, nil), 403, nil)
+
+		rec := ts.do(http.MethodPost, "/api/users/rehash", adminToken, nil, nil)
+		ts.expect(t, rec, 202, nil)
+
+		job := ts.waitForJob(t, adminToken, rec.Header().Get("Location"))
+		if job.Status != model.JobStatusSucceeded {
+			t.Fatalf("expected the check to succeed, got %+v", job)
+		}
+
+		var result struct {
+			Checked  int64 `json:"checked"`
+			Outdated int64 `json:"outdated"`
+		}
+		if err := json.Unmarshal(job.Result, &result); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if result.Checked == 0 || result.Outdated != 0 {
+			t.Errorf("expected every hash to use the current cost, got %+v", result)
+		}
+	})
+
+	step("audit events", func(t *testing.T) {
+		events := map[model.UserLogEvent]string{
+			model.UserLogEventRead:    admin.ID.String(),
+			model.UserLogEventCreate:  admin.ID.String(),
+			model.UserLogEventUpdate:  admin.ID.String(),
+			model.UserLogEventDelete:  admin.ID.String(),
+			model.UserLogEventRestore: admin.ID.String(),
+			model.UserLogEventImport:  jane.ID.String(),
+			model.UserLogEventExport:  admin.ID.String(),
+			model.UserLogEventPurge:   admin.ID.String(),
+		}
+
+		for event, actor := range events {
+			target := "/api/user-logs?sort=-created_at&filter[event][eq]=" + url.QueryEscape(event.String()) + "&filter[user_id][eq]=" + actor
+
+			var logs []*model.UserLogModel
+			eventually(t, event.String()+" by "+actor, func() bool {
+				ts.expect(t, ts.do(http.MethodGet, target, userToken, nil, nil), 200, &logs)
+				return len(logs) > 0
+			})
+
+			if logs[0].Event != event || logs[0].UserID != actor {
+				t.Errorf("unexpected %s log %+v", event, logs[0])
+			}
+		}
+
+		ts.expect(t, ts.do(http.MethodGet, "/api/user-logs?filter[level][eq]=info", userToken, nil, nil), 400, nil)
+	})
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	"codetest/internal/query"
+
+	"github.com/google/uuid"
+)
+
+// ErrAuditProblems is returned by audit verify when entries failed a check.
+var ErrAuditProblems = errors.New("audit log has problems")
+
+const auditPageSize = 100
+
+var knownUserLogEvents = map[model.UserLogEvent]bool{
+	model.UserLogEventRead:    true,
+	model.UserLogEventCreate:  true,
+	model.UserLogEventUpdate:  true,
+	model.UserLogEventDelete:  true,
+	model.UserLogEventRestore: true,
+	model.UserLogEventPurge:   true,
+	model.UserLogEventImport:  true,
+	model.UserLogEventExport:  true,
+}
+
+// auditVerify walks the user logs oldest first and reports entries with an
+// unknown event, a malformed actor or a missing or future timestamp.
+func auditVerify(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	maxProblems := set.Int("max-problems", 50, "number of problems printed, all are counted")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+
+	userLogService, err := env.userLogService()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	verified, problems := 0, 0
+	report := func(entry *model.UserLogModel, problem string) {
+		problems++
+		if problems <= *maxProblems {
+			fmt.Fprintf(env.out, "#%d %s %s by %q: %s\n", verified, entry.CreatedAt.Format(time.RFC3339), entry.Event, entry.UserID, problem)
+		}
+	}
+
+	// Oldest first, so entries written while verifying only add pages.
+	request := &dto.QueryUserLogRequest{
+		PageSize: auditPageSize,
+		Query:    &query.Query{Sorts: []query.Sort{{Field: "created_at", Column: "created_at"}}},
+	}
+	for request.Page = 1; ; request.Page++ {
+		entries, _, err := userLogService.Find(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			verified++
+
+			if !knownUserLogEvents[entry.Event] {
+				report(entry, "unknown event")
+			}
+
+			switch {
+			case entry.CreatedAt.IsZero():
+				report(entry, "missing created_at")
+			case entry.CreatedAt.After(now.Add(time.Minute)):
+				report(entry, "created_at is in the future")
+			}
+
+			if entry.UserID != model.UserLogActorCLI {
+				if _, err := uuid.Parse(entry.UserID); err != nil {
+					report(entry, "user_id is not a user ID")
+				}
+			}
+		}
+
+		if len(entries) < auditPageSize {
+			break
+		}
+	}
+
+	fmt.Fprintf(env.out, "Verified %d entries, %d problems\n", verified, problems)
+	if problems > 0 {
+		return ErrAuditProblems
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package cli implements the subcommands of the server binary.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"codetest/internal/config"
+	"codetest/internal/model"
+	"codetest/internal/requestctx"
+)
+
+var ErrUsage = errors.New("invalid usage")
+
+const usage = `Usage: app <command> [options]
+
+Commands:
+  serve [-demo]                start the HTTP server (default), -demo needs
+                               no database, Mongo or Redis
+  migrate up|down|status       apply, roll back or list migrations
+  seed                         create sample users or load a fixture file
+  user create                  create a user
+  user reset-password          set a new password for a user
+  user disable                 block a user from logging in
+  user promote                 change the role of a user
+  token issue                  issue tokens for a user, for debugging
+  audit verify                 check the user logs for malformed entries
+  config print                 print the configuration with secrets redacted
+
+Run "app <command> -h" for the options of a command.
+`
+
+type command func(ctx context.Context, env *env, args []string) error
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"serve":   serve,
+		"migrate": migrate,
+		"seed":    seed,
+		"user":    subcommands(map[string]command{"create": userCreate, "reset-password": userResetPassword, "disable": userDisable, "promote": userPromote}),
+		"token":   subcommands(map[string]command{"issue": tokenIssue}),
+		"audit":   subcommands(map[string]command{"verify": auditVerify}),
+		"config":  subcommands(map[string]command{"print": configPrint}),
+	}
+}
+
+// Run executes the command named by args[0], writing its output to out.
+// Usage errors wrap ErrUsage.
+func Run(ctx context.Context, cfg *config.AppConfig, args []string, out io.Writer) error {
+	env := &env{cfg: cfg, out: out}
+	defer env.close(ctx)
+
+	ctx = requestctx.WithActor(ctx, model.UserLogActorCLI)
+
+	return dispatch(ctx, env, commands, args)
+}
+
+func subcommands(cmds map[string]command) command {
+	return func(ctx context.Context, env *env, args []string) error {
+		return dispatch(ctx, env, cmds, args)
+	}
+}
+
+func dispatch(ctx context.Context, env *env, cmds map[string]command, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(env.out, usage)
+		return fmt.Errorf("%w: missing command", ErrUsage)
+	}
+
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(env.out, usage)
+		return nil
+	}
+
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		fmt.Fprint(env.out, usage)
+		return fmt.Errorf("%w: unknown command %q", ErrUsage, args[0])
+	}
+
+	return cmd(ctx, env, args[1:])
+}
+
+// parseFlags parses args into set, reporting problems as usage errors.
+func parseFlags(env *env, set *flag.FlagSet, args []string) error {
+	set.SetOutput(env.out)
+	if err := set.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ErrUsage
+		}
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+
+	if set.NArg() > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", ErrUsage, set.Arg(0))
+	}
+	return nil
+}
+
+// required fails with a usage error when any of the named flags is empty.
+func required(set *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if set.Lookup(name).Value.String() == "" {
+			return fmt.Errorf("%w: -%s is required", ErrUsage, name)
+		}
+	}
+	return nil
+}
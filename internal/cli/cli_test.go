@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"codetest/internal/config"
+)
+
+func TestRun_Usage(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		printsHelp bool
+	}{
+		{name: "no command", args: nil, printsHelp: true},
+		{name: "unknown command", args: []string{"frobnicate"}, printsHelp: true},
+		{name: "unknown subcommand", args: []string{"user", "delete"}, printsHelp: true},
+		{name: "migrate without action", args: []string{"migrate"}},
+		{name: "unknown migrate action", args: []string{"migrate", "sideways"}},
+		{name: "missing required flag", args: []string{"user", "create", "-name", "Jane"}},
+		{name: "invalid role", args: []string{"user", "promote", "-email", "jane@example.com", "-role", "root"}},
+		{name: "short password", args: []string{"user", "create", "-name", "Jane", "-email", "jane@example.com", "-password", "abc"}},
+		{name: "unknown flag", args: []string{"seed", "-size", "10"}},
+		{name: "invalid count", args: []string{"seed", "-count", "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			err := Run(context.Background(), &config.AppConfig{}, tt.args, &out)
+			if !errors.Is(err, ErrUsage) {
+				t.Errorf("expected usage error, got %v", err)
+			}
+
+			if printed := strings.Contains(out.String(), "Commands:"); printed != tt.printsHelp {
+				t.Errorf("expected usage printed to be %v, got %q", tt.printsHelp, out.String())
+			}
+		})
+	}
+}
+
+func TestRun_ConfigPrint(t *testing.T) {
+	var out bytes.Buffer
+
+	err := Run(context.Background(), &config.AppConfig{PORT: "8080", ACCESS_TOKEN_KEY: "secret"}, []string{"config", "print"}, &out)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !strings.Contains(out.String(), "PORT=8080\n") {
+		t.Errorf("expected PORT to be printed, got %q", out.String())
+	}
+
+	if strings.Contains(out.String(), "secret") || !strings.Contains(out.String(), "ACCESS_TOKEN_KEY=REDACTED\n") {
+		t.Errorf("expected ACCESS_TOKEN_KEY to be redacted, got %q", out.String())
+	}
+}
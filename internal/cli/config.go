@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+func configPrint(ctx context.Context, env *env, args []string) error {
+	if err := parseFlags(env, flag.NewFlagSet("config print", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	for _, entry := range env.cfg.Entries() {
+		fmt.Fprintf(env.out, "%s=%s\n", entry.Name, entry.Value)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"context"
+	"io"
+
+	"codetest/internal/adapter/publisher"
+	"codetest/internal/adapter/repository/cache"
+	"codetest/internal/adapter/repository/fanout"
+	"codetest/internal/adapter/repository/file"
+	"codetest/internal/adapter/repository/gorm"
+	mongorepository "codetest/internal/adapter/repository/mongo"
+	"codetest/internal/adapter/service"
+	"codetest/internal/config"
+	"codetest/internal/model"
+	"codetest/internal/persistent/database"
+	"codetest/internal/persistent/mongo"
+	"codetest/internal/persistent/redis"
+	portrepository "codetest/internal/port/repository"
+	portservice "codetest/internal/port/service"
+)
+
+// env opens the connections a command needs on first use and closes them
+// once the command returns.
+type env struct {
+	cfg *config.AppConfig
+	out io.Writer
+
+	dbConn    *database.DBConnection
+	mongoConn *mongo.DBConnection
+	redisConn *redis.RedisConnection
+
+	userLogs portservice.UserLogService
+	events   portservice.EventPublisher
+}
+
+func (e *env) db() (*database.DBConnection, error) {
+	if e.dbConn == nil {
+		conn, err := database.NewDBConnection(e.cfg)
+		if err != nil {
+			return nil, err
+		}
+		e.dbConn = conn
+	}
+	return e.dbConn, nil
+}
+
+// userRepository mirrors the server wiring, so changes made here also evict
+// the users cached by running servers.
+func (e *env) userRepository() (portrepository.UserRepository, error) {
+	conn, err := e.db()
+	if err != nil {
+		return nil, err
+	}
+
+	userRepository := gorm.NewUserRepository(conn.GetDBInstance())
+	if e.cfg.USER_CACHE_ENABLED {
+		if e.redisConn == nil {
+			if e.redisConn, err = redis.NewRedisConnection(e.cfg); err != nil {
+				return nil, err
+			}
+		}
+		userRepository = cache.NewUserRepository(userRepository, e.redisConn.GetRedisInstance(), e.cfg.USER_CACHE_TTL)
+	}
+
+	return userRepository, nil
+}
+
+func (e *env) userService() (portservice.UserService, error) {
+	userRepository, err := e.userRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	return service.NewUserService(userRepository, e.eventPublisher(), e.cfg.PASSWORD_HASH_COST), nil
+}
+
+// eventPublisher stores the user log events of the command straight into the
+// sinks, there is no server side consumer to go through.
+func (e *env) eventPublisher() portservice.EventPublisher {
+	if e.events == nil {
+		if e.cfg.USER_LOG_PUBLISHER == config.UserLogPublisherNone {
+			e.events = publisher.NewNoopPublisher()
+		} else {
+			events := publisher.NewMemoryPublisher()
+			events.Subscribe(func(ctx context.Context, userLog *model.UserLogModel) error {
+				userLogService, err := e.userLogService()
+				if err != nil {
+					return err
+				}
+				return userLogService.Create(ctx, userLog)
+			})
+			e.events = events
+		}
+	}
+	return e.events
+}
+
+// userLogService writes to the sinks of USER_LOG_SINKS like the server.
+func (e *env) userLogService() (portservice.UserLogService, error) {
+	if e.userLogs != nil {
+		return e.userLogs, nil
+	}
+
+	var repositories []portrepository.UserLogRepository
+	for _, sink := range e.cfg.UserLogSinks() {
+		switch sink {
+		case config.UserLogSinkMongo:
+			if e.mongoConn == nil {
+				conn, err := mongo.NewMongoDBConnection(e.cfg)
+				if err != nil {
+					return nil, err
+				}
+				e.mongoConn = conn
+			}
+			repositories = append(repositories, mongorepository.NewUserLogRepository(e.mongoConn.Client, e.cfg.MONGODB_DATABASE, e.cfg.MONGODB_USER_LOG_COLLECTION))
+		case config.UserLogSinkDatabase:
+			conn, err := e.db()
+			if err != nil {
+				return nil, err
+			}
+			repositories = append(repositories, gorm.NewUserLogRepository(conn.GetDBInstance()))
+		case config.UserLogSinkFile:
+			repository, err := file.NewUserLogRepository(e.cfg.USER_LOG_FILE_PATH, int64(e.cfg.USER_LOG_FILE_MAX_SIZE_MB)<<20, e.cfg.USER_LOG_FILE_MAX_BACKUPS)
+			if err != nil {
+				return nil, err
+			}
+			repositories = append(repositories, repository)
+		}
+	}
+
+	e.userLogs = service.NewUserLogService(fanout.NewUserLogRepository(repositories...))
+	return e.userLogs, nil
+}
+
+func (e *env) close(ctx context.Context) {
+	if e.dbConn != nil {
+		_ = e.dbConn.Close()
+	}
+	if e.mongoConn != nil {
+		_ = e.mongoConn.Close(ctx)
+	}
+	if e.redisConn != nil {
+		_ = e.redisConn.Close()
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pressly/goose/v3"
+)
+
+func migrate(ctx context.Context, env *env, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: migrate expects one of up, down or status", ErrUsage)
+	}
+
+	var run func(context.Context, *goose.Provider, io.Writer) error
+	switch args[0] {
+	case "up":
+		run = migrateUp
+	case "down":
+		run = migrateDown
+	case "status":
+		run = migrateStatus
+	default:
+		return fmt.Errorf("%w: unknown migrate action %q", ErrUsage, args[0])
+	}
+
+	conn, err := env.db()
+	if err != nil {
+		return err
+	}
+
+	migrator, err := conn.NewMigrator()
+	if err != nil {
+		return err
+	}
+
+	return run(ctx, migrator, env.out)
+}
+
+func migrateUp(ctx context.Context, migrator *goose.Provider, out io.Writer) error {
+	results, err := migrator.Up(ctx)
+	for _, result := range results {
+		fmt.Fprintf(out, "OK   %s (%s)\n", result.Source.Path, result.Duration.Round(time.Millisecond))
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(out, "No pending migrations")
+	}
+	return nil
+}
+
+func migrateDown(ctx context.Context, migrator *goose.Provider, out io.Writer) error {
+	result, err := migrator.Down(ctx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "OK   %s (%s)\n", result.Source.Path, result.Duration.Round(time.Millisecond))
+	return nil
+}
+
+func migrateStatus(ctx context.Context, migrator *goose.Provider, out io.Writer) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "APPLIED AT\tMIGRATION")
+	for _, status := range statuses {
+		appliedAt := "Pending"
+		if status.State == goose.StateApplied {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", appliedAt, status.Source.Path)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"codetest/internal/adapter/api/dto"
+)
+
+func seed(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("seed", flag.ContinueOnError)
+	count := set.Int("count", 100, "number of sample users to create")
+	file := set.String("file", "", "csv or ndjson fixture imported instead of sample users")
+	format := set.String("format", "", "fixture format, csv or ndjson, guessed from the extension by default")
+	password := set.String("password", "password", "password of the sample users")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+
+	if *file != "" {
+		return seedFile(ctx, env, *file, *format)
+	}
+
+	if *count < 1 {
+		return fmt.Errorf("%w: -count must be positive", ErrUsage)
+	}
+	return seedSample(ctx, env, *count, *password)
+}
+
+// seedFile loads a fixture through the same validation as the import API.
+func seedFile(ctx context.Context, env *env, path, format string) error {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".ndjson", ".jsonl":
+			format = dto.ImportFormatNDJSON
+		default:
+			format = dto.ImportFormatCSV
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	userService, err := env.userService()
+	if err != nil {
+		return err
+	}
+
+	report, err := userService.Import(ctx, file, filepath.Base(path), format, false)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range report.Rows {
+		if !row.Success {
+			fmt.Fprintf(env.out, "row %d %s: %v\n", row.Row, row.Email, row.Errors)
+		}
+	}
+	fmt.Fprintf(env.out, "Imported %d of %d users from %s\n", report.Succeeded, report.Total, path)
+	return nil
+}
+
+// seedSample creates count users named "User <n>", skipping emails that
+// already exist so seeding twice is harmless.
+func seedSample(ctx context.Context, env *env, count int, password string) error {
+	userService, err := env.userService()
+	if err != nil {
+		return err
+	}
+
+	requests := make([]*dto.CreateUserRequest, 0, count)
+	for i := range count {
+		requests = append(requests, &dto.CreateUserRequest{
+			Name:     fmt.Sprintf("User %d", i),
+			Email:    fmt.Sprintf("user%d@example.com", i),
+			Password: password,
+		})
+	}
+
+	created, err := userService.CreateMany(ctx, requests)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.out, "Created %d users, %d already existed\n", created, count-created)
+	return nil
+}
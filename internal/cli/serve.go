@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"context"
+	"flag"
+
+	"codetest/internal/app"
+)
+
+func serve(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("serve", flag.ContinueOnError)
+	demo := set.Bool("demo", false, "keep data in memory and embed Redis, seeded with sample users")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+
+	newServerApp := app.NewServerApp
+	if *demo {
+		newServerApp = app.NewDemoServerApp
+	}
+
+	server, err := newServerApp(env.cfg)
+	if err != nil {
+		return err
+	}
+
+	server.Run()
+	return nil
+}
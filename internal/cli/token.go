@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"codetest/internal/adapter/service"
+)
+
+func tokenIssue(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("token issue", flag.ContinueOnError)
+	email := set.String("email", "", "email address of the user")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+	if err := required(set, "email"); err != nil {
+		return err
+	}
+
+	_, user, err := findUser(ctx, env, *email)
+	if err != nil {
+		return err
+	}
+
+	jwtService := service.NewJWTService(env.cfg)
+
+	accessToken, err := jwtService.GenerateAccessToken(user)
+	if err != nil {
+		return err
+	}
+
+	refreshToken, err := jwtService.GenerateRefreshToken(user)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.out, "Access token:  %s\nRefresh token: %s\n", accessToken, refreshToken)
+	return nil
+}
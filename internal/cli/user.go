@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+
+	"codetest/internal/adapter/api/dto"
+	"codetest/internal/model"
+	portservice "codetest/internal/port/service"
+)
+
+const minPasswordLength = 6
+
+func userCreate(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("user create", flag.ContinueOnError)
+	name := set.String("name", "", "display name")
+	email := set.String("email", "", "email address")
+	passwordFlag := set.String("password", "", "password, generated and printed when empty")
+	role := set.String("role", model.UserRoleUser.String(), "user or admin")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+	if err := required(set, "name", "email"); err != nil {
+		return err
+	}
+	if err := validateRole(*role); err != nil {
+		return err
+	}
+
+	password, generated, err := passwordOrGenerate(*passwordFlag)
+	if err != nil {
+		return err
+	}
+
+	userService, err := env.userService()
+	if err != nil {
+		return err
+	}
+
+	if err := userService.Create(ctx, &dto.CreateUserRequest{Name: *name, Email: *email, Password: password}); err != nil {
+		return err
+	}
+
+	user, err := userService.GetOneByEmail(ctx, *email)
+	if err != nil {
+		return err
+	}
+
+	if model.UserRole(*role) != user.Role {
+		if err := userService.SetRole(ctx, user.ID, model.UserRole(*role)); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(env.out, "Created %s user %s (%s)\n", *role, user.Email, user.ID)
+	if generated {
+		fmt.Fprintf(env.out, "Password: %s\n", password)
+	}
+	return nil
+}
+
+func userResetPassword(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("user reset-password", flag.ContinueOnError)
+	email := set.String("email", "", "email address of the user")
+	passwordFlag := set.String("password", "", "new password, generated and printed when empty")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+	if err := required(set, "email"); err != nil {
+		return err
+	}
+
+	password, generated, err := passwordOrGenerate(*passwordFlag)
+	if err != nil {
+		return err
+	}
+
+	userService, user, err := findUser(ctx, env, *email)
+	if err != nil {
+		return err
+	}
+
+	if err := userService.ResetPassword(ctx, user.ID, password); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.out, "Password of %s was reset\n", user.Email)
+	if generated {
+		fmt.Fprintf(env.out, "Password: %s\n", password)
+	}
+	return nil
+}
+
+func userDisable(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("user disable", flag.ContinueOnError)
+	email := set.String("email", "", "email address of the user")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+	if err := required(set, "email"); err != nil {
+		return err
+	}
+
+	userService, user, err := findUser(ctx, env, *email)
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt != nil {
+		fmt.Fprintf(env.out, "%s is already disabled\n", user.Email)
+		return nil
+	}
+
+	if err := userService.Disable(ctx, user.ID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.out, "Disabled %s, issued access tokens stay valid until they expire\n", user.Email)
+	return nil
+}
+
+func userPromote(ctx context.Context, env *env, args []string) error {
+	set := flag.NewFlagSet("user promote", flag.ContinueOnError)
+	email := set.String("email", "", "email address of the user")
+	role := set.String("role", model.UserRoleAdmin.String(), "role to grant, user or admin")
+	if err := parseFlags(env, set, args); err != nil {
+		return err
+	}
+	if err := required(set, "email"); err != nil {
+		return err
+	}
+	if err := validateRole(*role); err != nil {
+		return err
+	}
+
+	userService, user, err := findUser(ctx, env, *email)
+	if err != nil {
+		return err
+	}
+
+	if err := userService.SetRole(ctx, user.ID, model.UserRole(*role)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.out, "%s is now %s\n", user.Email, *role)
+	return nil
+}
+
+func findUser(ctx context.Context, env *env, email string) (portservice.UserService, *model.UserModel, error) {
+	userService, err := env.userService()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := userService.GetOneByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, fmt.Errorf("user %s: %w", email, err)
+	}
+
+	return userService, user, nil
+}
+
+func validateRole(role string) error {
+	switch model.UserRole(role) {
+	case model.UserRoleUser, model.UserRoleAdmin:
+		return nil
+	default:
+		return fmt.Errorf("%w: -role must be user or admin", ErrUsage)
+	}
+}
+
+// passwordOrGenerate returns password, or a random one when it is empty.
+func passwordOrGenerate(password string) (string, bool, error) {
+	if password != "" {
+		if len(password) < minPasswordLength {
+			return "", false, fmt.Errorf("%w: -password must have at least %d characters", ErrUsage, minPasswordLength)
+		}
+		return password, false, nil
+	}
+
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), true, nil
+}
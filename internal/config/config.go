@@ -1,39 +1,116 @@
 package config
 
-import "github.com/caarlos0/env/v11"
+import (
+	"strings"
+	"time"
+)
+
+// User log sinks accepted in USER_LOG_SINKS.
+const (
+	UserLogSinkMongo    = "mongo"
+	UserLogSinkDatabase = "database"
+	UserLogSinkFile     = "file"
+)
+
+// User log publishers accepted in USER_LOG_PUBLISHER.
+const (
+	UserLogPublisherRedis  = "redis"
+	UserLogPublisherMemory = "memory"
+	UserLogPublisherNone   = "none"
+)
 
 type AppConfig struct {
-	GO_ENV                 string `env:"GO_ENV" envDefault:"development"`
-	PORT                   string `env:"PORT" envDefault:"8080"`
-	MODE                   string `env:"MODE" envDefault:"debug"`
-	POSTGRES_USERNAME      string `env:"POSTGRES_USERNAME"`
-	POSTGRES_PASSWORD      string `env:"POSTGRES_PASSWORD"`
-	POSTGRES_HOST          string `env:"POSTGRES_HOST"`
-	POSTGRES_PORT          string `env:"POSTGRES_PORT" envDefault:"5432"`
-	POSTGRES_DB            string `env:"POSTGRES_DB"`
-	POSTGRES_SSLMODE       string `env:"POSTGRES_SSLMODE" envDefault:"disable"`
-	MONGODB_URI            string `env:"MONGODB_URI" envDefault:"mongodb://localhost:27017"`
-	REDIS_ADDRESS          string `env:"REDIS_ADDRESS" envDefault:"localhost:6379"`
-	REDIS_PASSWORD         string `env:"REDIS_PASSWORD" envDefault:""`
-	REDIS_DB               int    `env:"REDIS_DB" envDefault:"0"`
-	REDIS_USER_LOG_CHANNEL string `env:"REDIS_USER_LOG_CHANNEL" envDefault:"user_log_channel"`
-	ACCESS_TOKEN_KEY       string `env:"ACCESS_TOKEN_KEY" envDefault:"secret"`
-	ACCESS_TOKEN_TTL       int    `env:"ACCESS_TOKEN_TTL" envDefault:"3600"`
-	REFRESH_TOKEN_KEY      string `env:"REFRESH_TOKEN_KEY" envDefault:"refresh_secret"`
-	REFRESH_TOKEN_TTL      int    `env:"REFRESH_TOKEN_TTL" envDefault:"86400"`
-	CORS_ALLOWED_ORIGINS   string `env:"CORS_ALLOWED_ORIGINS"`
-	CORS_ALLOWED_METHODS   string `env:"CORS_ALLOWED_METHODS" envDefault:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
-	CORS_ALLOWED_HEADERS   string `env:"CORS_ALLOWED_HEADERS"`
-	CORS_EXPOSED_HEADERS   string `env:"CORS_EXPOSED_HEADERS"`
-	CORS_ALLOW_CREDENTIALS bool   `env:"CORS_ALLOW_CREDENTIALS" envDefault:"true"`
+	CONFIG_FILE                      string        `env:"CONFIG_FILE"`
+	GO_ENV                           string        `env:"GO_ENV" envDefault:"development"`
+	PORT                             string        `env:"PORT" envDefault:"8080"`
+	MODE                             string        `env:"MODE" envDefault:"debug"`
+	LOG_LEVEL                        string        `env:"LOG_LEVEL" envDefault:"info"`
+	LOG_FORMAT                       string        `env:"LOG_FORMAT" envDefault:"json"`
+	OTEL_SERVICE_NAME                string        `env:"OTEL_SERVICE_NAME" envDefault:"codetest"`
+	TRACING_EXPORTER                 string        `env:"TRACING_EXPORTER" envDefault:"none"`
+	TRACING_FILE                     string        `env:"TRACING_FILE" envDefault:"traces.ndjson"`
+	TRACING_SAMPLE_RATIO             float64       `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	METRICS_PORT                     string        `env:"METRICS_PORT"`
+	METRICS_TOKEN                    string        `env:"METRICS_TOKEN" redact:"true"`
+	DB_DRIVER                        string        `env:"DB_DRIVER" envDefault:"postgres"`
+	DB_MAX_OPEN_CONNS                int           `env:"DB_MAX_OPEN_CONNS" envDefault:"100"`
+	DB_MAX_IDLE_CONNS                int           `env:"DB_MAX_IDLE_CONNS" envDefault:"10"`
+	DB_CONN_MAX_LIFETIME             time.Duration `env:"DB_CONN_MAX_LIFETIME" envDefault:"1h"`
+	DB_CONN_MAX_IDLE_TIME            time.Duration `env:"DB_CONN_MAX_IDLE_TIME" envDefault:"0s"`
+	DB_QUERY_TIMEOUT                 time.Duration `env:"DB_QUERY_TIMEOUT" envDefault:"30s"`
+	POSTGRES_USERNAME                string        `env:"POSTGRES_USERNAME"`
+	POSTGRES_PASSWORD                string        `env:"POSTGRES_PASSWORD" redact:"true"`
+	POSTGRES_HOST                    string        `env:"POSTGRES_HOST"`
+	POSTGRES_PORT                    string        `env:"POSTGRES_PORT" envDefault:"5432"`
+	POSTGRES_DB                      string        `env:"POSTGRES_DB"`
+	POSTGRES_SSLMODE                 string        `env:"POSTGRES_SSLMODE" envDefault:"disable"`
+	POSTGRES_APPLICATION_NAME        string        `env:"POSTGRES_APPLICATION_NAME" envDefault:"codetest"`
+	POSTGRES_STATEMENT_TIMEOUT       time.Duration `env:"POSTGRES_STATEMENT_TIMEOUT" envDefault:"0s"`
+	POSTGRES_REPLICA_HOSTS           string        `env:"POSTGRES_REPLICA_HOSTS"`
+	SQLITE_PATH                      string        `env:"SQLITE_PATH" envDefault:"codetest.db"`
+	MYSQL_USERNAME                   string        `env:"MYSQL_USERNAME"`
+	MYSQL_PASSWORD                   string        `env:"MYSQL_PASSWORD" redact:"true"`
+	MYSQL_HOST                       string        `env:"MYSQL_HOST"`
+	MYSQL_PORT                       string        `env:"MYSQL_PORT" envDefault:"3306"`
+	MYSQL_DB                         string        `env:"MYSQL_DB"`
+	MIGRATE_ON_BOOT                  bool          `env:"MIGRATE_ON_BOOT" envDefault:"false"`
+	MONGODB_URI                      string        `env:"MONGODB_URI" envDefault:"mongodb://localhost:27017" redact:"url"`
+	MONGODB_DATABASE                 string        `env:"MONGODB_DATABASE" envDefault:"test"`
+	MONGODB_USER_LOG_COLLECTION      string        `env:"MONGODB_USER_LOG_COLLECTION" envDefault:"user_logs"`
+	MONGODB_MAX_POOL_SIZE            uint64        `env:"MONGODB_MAX_POOL_SIZE" envDefault:"100"`
+	MONGODB_MIN_POOL_SIZE            uint64        `env:"MONGODB_MIN_POOL_SIZE" envDefault:"0"`
+	MONGODB_CONNECT_TIMEOUT          time.Duration `env:"MONGODB_CONNECT_TIMEOUT" envDefault:"10s"`
+	MONGODB_SERVER_SELECTION_TIMEOUT time.Duration `env:"MONGODB_SERVER_SELECTION_TIMEOUT" envDefault:"5s"`
+	MONGODB_WRITE_CONCERN            string        `env:"MONGODB_WRITE_CONCERN"`
+	MONGODB_READ_PREFERENCE          string        `env:"MONGODB_READ_PREFERENCE"`
+	USER_LOG_SINKS                   string        `env:"USER_LOG_SINKS" envDefault:"mongo"`
+	USER_LOG_FILE_PATH               string        `env:"USER_LOG_FILE_PATH" envDefault:"storage/user-logs.ndjson"`
+	USER_LOG_FILE_MAX_SIZE_MB        int           `env:"USER_LOG_FILE_MAX_SIZE_MB" envDefault:"100"`
+	USER_LOG_FILE_MAX_BACKUPS        int           `env:"USER_LOG_FILE_MAX_BACKUPS" envDefault:"5"`
+	REDIS_ADDRESS                    string        `env:"REDIS_ADDRESS" envDefault:"localhost:6379"`
+	REDIS_PASSWORD                   string        `env:"REDIS_PASSWORD" envDefault:"" redact:"true"`
+	REDIS_DB                         int           `env:"REDIS_DB" envDefault:"0"`
+	REDIS_USER_LOG_CHANNEL           string        `env:"REDIS_USER_LOG_CHANNEL" envDefault:"user_log_channel"`
+	USER_LOG_PUBLISHER               string        `env:"USER_LOG_PUBLISHER" envDefault:"redis"`
+	ACCESS_TOKEN_KEY                 string        `env:"ACCESS_TOKEN_KEY" envDefault:"secret" redact:"true"`
+	ACCESS_TOKEN_TTL                 time.Duration `env:"ACCESS_TOKEN_TTL" envDefault:"1h"`
+	REFRESH_TOKEN_KEY                string        `env:"REFRESH_TOKEN_KEY" envDefault:"refresh_secret" redact:"true"`
+	REFRESH_TOKEN_TTL                time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"24h"`
+	PASSWORD_HASH_COST               int           `env:"PASSWORD_HASH_COST" envDefault:"10"`
+	CORS_ALLOWED_ORIGINS             string        `env:"CORS_ALLOWED_ORIGINS"`
+	CORS_ALLOWED_METHODS             string        `env:"CORS_ALLOWED_METHODS" envDefault:"GET,POST,PUT,PATCH,DELETE,OPTIONS"`
+	CORS_ALLOWED_HEADERS             string        `env:"CORS_ALLOWED_HEADERS"`
+	CORS_EXPOSED_HEADERS             string        `env:"CORS_EXPOSED_HEADERS"`
+	CORS_ALLOW_CREDENTIALS           bool          `env:"CORS_ALLOW_CREDENTIALS" envDefault:"true"`
+	TRUSTED_PROXIES                  string        `env:"TRUSTED_PROXIES"`
+	RATE_LIMIT_WINDOW                time.Duration `env:"RATE_LIMIT_WINDOW" envDefault:"1m"`
+	RATE_LIMIT_AUTH                  int           `env:"RATE_LIMIT_AUTH" envDefault:"20"`
+	RATE_LIMIT_USERS                 int           `env:"RATE_LIMIT_USERS" envDefault:"300"`
+	RATE_LIMIT_USER_LOGS             int           `env:"RATE_LIMIT_USER_LOGS" envDefault:"300"`
+	RATE_LIMIT_ALLOWLIST             string        `env:"RATE_LIMIT_ALLOWLIST"`
+	IDEMPOTENCY_TTL                  time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+	IDEMPOTENCY_MAX_BODY_SIZE        int64         `env:"IDEMPOTENCY_MAX_BODY_SIZE" envDefault:"16777216"`
+	USER_CACHE_ENABLED               bool          `env:"USER_CACHE_ENABLED" envDefault:"false"`
+	USER_CACHE_TTL                   time.Duration `env:"USER_CACHE_TTL" envDefault:"5m"`
+	JOB_WORKERS                      int           `env:"JOB_WORKERS" envDefault:"2"`
+	JOB_POLL_INTERVAL                time.Duration `env:"JOB_POLL_INTERVAL" envDefault:"2s"`
+	JOB_STALE_AFTER                  time.Duration `env:"JOB_STALE_AFTER" envDefault:"10m"`
+	JOB_MAX_ATTEMPTS                 int           `env:"JOB_MAX_ATTEMPTS" envDefault:"3"`
+	JOB_ARTIFACT_DIR                 string        `env:"JOB_ARTIFACT_DIR" envDefault:"storage/jobs"`
+	JOB_ARTIFACT_RETENTION           time.Duration `env:"JOB_ARTIFACT_RETENTION" envDefault:"168h"`
+	HEALTH_CHECK_TIMEOUT             time.Duration `env:"HEALTH_CHECK_TIMEOUT" envDefault:"2s"`
+	HEALTH_CACHE_TTL                 time.Duration `env:"HEALTH_CACHE_TTL" envDefault:"2s"`
+	SHUTDOWN_DELAY                   time.Duration `env:"SHUTDOWN_DELAY" envDefault:"0s"`
 }
 
-var config AppConfig
-
-func NewAppConfig() *AppConfig {
-	if err := env.Parse(&config); err != nil {
-		panic(err)
+// UserLogSinks lists the sinks of USER_LOG_SINKS in order, the first one able
+// to read serves the user logs endpoint.
+func (c *AppConfig) UserLogSinks() []string {
+	var sinks []string
+	for _, sink := range strings.Split(c.USER_LOG_SINKS, ",") {
+		if sink = strings.TrimSpace(sink); sink != "" {
+			sinks = append(sinks, sink)
+		}
 	}
-
-	return &config
+	return sinks
 }
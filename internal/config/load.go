@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// renamedVars maps settings renamed since they were introduced to their
+// current name. The old name is still read when the new one is not set.
+var renamedVars = map[string]string{
+	"POSTGRES_MAX_OPEN_CONNS":     "DB_MAX_OPEN_CONNS",
+	"POSTGRES_MAX_IDLE_CONNS":     "DB_MAX_IDLE_CONNS",
+	"POSTGRES_CONN_MAX_LIFETIME":  "DB_CONN_MAX_LIFETIME",
+	"POSTGRES_CONN_MAX_IDLE_TIME": "DB_CONN_MAX_IDLE_TIME",
+	"POSTGRES_QUERY_TIMEOUT":      "DB_QUERY_TIMEOUT",
+}
+
+// Error lists every problem found while loading the configuration.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// NewAppConfig reads the configuration from the environment, layered over
+// the YAML or TOML file named by CONFIG_FILE, and validates it.
+func NewAppConfig() (*AppConfig, error) {
+	environment := make(map[string]string)
+	for _, pair := range os.Environ() {
+		if key, value, ok := strings.Cut(pair, "="); ok {
+			environment[key] = value
+		}
+	}
+
+	return load(environment)
+}
+
+func load(environment map[string]string) (*AppConfig, error) {
+	vars := make(map[string]string, len(environment))
+	for key, value := range environment {
+		vars[key] = value
+	}
+
+	if path := vars["CONFIG_FILE"]; path != "" {
+		fileVars, err := readFile(path)
+		if err != nil {
+			return nil, &Error{Problems: []string{fmt.Sprintf("CONFIG_FILE: %v", err)}}
+		}
+
+		// Environment variables win over the file, unless left empty.
+		for key, value := range fileVars {
+			if vars[key] == "" {
+				vars[key] = value
+			}
+		}
+	}
+
+	for old, current := range renamedVars {
+		if vars[current] == "" && vars[old] != "" {
+			vars[current] = vars[old]
+		}
+	}
+
+	var problems []string
+	problems = append(problems, resolveSecretFiles(vars)...)
+
+	cfg := &AppConfig{}
+	err := env.ParseWithOptions(cfg, env.Options{
+		Environment: vars,
+		FuncMap: map[reflect.Type]env.ParserFunc{
+			reflect.TypeOf(time.Duration(0)): parseDuration,
+		},
+	})
+	var aggregate env.AggregateError
+	switch {
+	case errors.As(err, &aggregate):
+		for _, err := range aggregate.Errors {
+			problems = append(problems, err.Error())
+		}
+	case err != nil:
+		problems = append(problems, err.Error())
+	}
+
+	if len(problems) == 0 {
+		problems = cfg.validate()
+	}
+
+	if len(problems) > 0 {
+		return nil, &Error{Problems: problems}
+	}
+
+	return cfg, nil
+}
+
+// parseDuration accepts Go durations such as "90s" or "1h" and, for
+// compatibility with older deployments, a bare number of seconds.
+func parseDuration(value string) (interface{}, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// readFile flattens a config file into environment variables. Keys are
+// matched case insensitively and lists are joined with commas.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &values)
+	case ".toml":
+		err = toml.Unmarshal(content, &values)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, expected .yaml, .yml or .toml", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string, len(values))
+	for key, value := range values {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			return nil, fmt.Errorf("%s: nested sections are not supported", key)
+		case []interface{}:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			vars[strings.ToUpper(key)] = strings.Join(items, ",")
+		case nil:
+			vars[strings.ToUpper(key)] = ""
+		default:
+			vars[strings.ToUpper(key)] = fmt.Sprint(value)
+		}
+	}
+
+	return vars, nil
+}
+
+// resolveSecretFiles reads <NAME>_FILE into NAME for every secret setting,
+// the way Docker and Kubernetes mount secrets.
+func resolveSecretFiles(vars map[string]string) []string {
+	var problems []string
+
+	fields := reflect.TypeOf(AppConfig{})
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		if field.Tag.Get("redact") == "" {
+			continue
+		}
+
+		name := field.Tag.Get("env")
+		path := vars[name+"_FILE"]
+		if path == "" {
+			continue
+		}
+
+		if vars[name] != "" {
+			problems = append(problems, fmt.Sprintf("%s and %s_FILE are both set", name, name))
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s_FILE: %v", name, err))
+			continue
+		}
+
+		vars[name] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return problems
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func problemsOf(t *testing.T, err error) []string {
+	t.Helper()
+
+	var cfgErr *Error
+	if !errors.As(err, &cfgErr) {
+		t.Fatalf("expected a configuration error, got %v", err)
+	}
+	return cfgErr.Problems
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(map[string]string{})
+	if err != nil {
+		t.Fatalf("expected development defaults to be valid, got %v", err)
+	}
+
+	if cfg.ACCESS_TOKEN_TTL != time.Hour || cfg.JOB_POLL_INTERVAL != 2*time.Second {
+		t.Errorf("unexpected default durations %s and %s", cfg.ACCESS_TOKEN_TTL, cfg.JOB_POLL_INTERVAL)
+	}
+}
+
+func TestLoad_Durations(t *testing.T) {
+	cfg, err := load(map[string]string{"ACCESS_TOKEN_TTL": "900", "REFRESH_TOKEN_TTL": "36h"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.ACCESS_TOKEN_TTL != 15*time.Minute {
+		t.Errorf("expected bare numbers to be seconds, got %s", cfg.ACCESS_TOKEN_TTL)
+	}
+
+	if cfg.REFRESH_TOKEN_TTL != 36*time.Hour {
+		t.Errorf("expected 36h, got %s", cfg.REFRESH_TOKEN_TTL)
+	}
+
+	_, err = load(map[string]string{"IDEMPOTENCY_TTL": "a day"})
+	if problems := problemsOf(t, err); len(problems) != 1 || !strings.Contains(problems[0], "IDEMPOTENCY_TTL") {
+		t.Errorf("expected IDEMPOTENCY_TTL to be reported, got %v", problems)
+	}
+}
+
+func TestLoad_ConfigFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "yaml",
+			file:    "config.yaml",
+			content: "port: 8081\nlog_level: debug\ncors_allowed_origins:\n  - https://a.example.com\n  - https://b.example.com\n",
+		},
+		{
+			name:    "toml",
+			file:    "config.toml",
+			content: "PORT = 8081\nLOG_LEVEL = \"debug\"\nCORS_ALLOWED_ORIGINS = [\"https://a.example.com\", \"https://b.example.com\"]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.file, tt.content)
+
+			cfg, err := load(map[string]string{"CONFIG_FILE": path, "LOG_LEVEL": "warn"})
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if cfg.PORT != "8081" {
+				t.Errorf("expected PORT from the file, got %q", cfg.PORT)
+			}
+
+			if cfg.LOG_LEVEL != "warn" {
+				t.Errorf("expected the environment to win over the file, got %q", cfg.LOG_LEVEL)
+			}
+
+			if cfg.CORS_ALLOWED_ORIGINS != "https://a.example.com,https://b.example.com" {
+				t.Errorf("expected lists to be joined, got %q", cfg.CORS_ALLOWED_ORIGINS)
+			}
+		})
+	}
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		_, err := load(map[string]string{"CONFIG_FILE": writeFile(t, "config.ini", "PORT=1")})
+		if problems := problemsOf(t, err); !strings.HasPrefix(problems[0], "CONFIG_FILE:") {
+			t.Errorf("expected CONFIG_FILE to be reported, got %v", problems)
+		}
+	})
+}
+
+func TestLoad_SecretFiles(t *testing.T) {
+	path := writeFile(t, "postgres_password", "s3cret\n")
+
+	cfg, err := load(map[string]string{"POSTGRES_PASSWORD_FILE": path})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if cfg.POSTGRES_PASSWORD != "s3cret" {
+		t.Errorf("expected the secret without its trailing newline, got %q", cfg.POSTGRES_PASSWORD)
+	}
+
+	_, err = load(map[string]string{"POSTGRES_PASSWORD_FILE": path, "POSTGRES_PASSWORD": "other"})
+	if problems := problemsOf(t, err); len(problems) != 1 || !strings.Contains(problems[0], "both set") {
+		t.Errorf("expected a conflict to be reported, got %v", problems)
+	}
+
+	_, err = load(map[string]string{"ACCESS_TOKEN_KEY_FILE": filepath.Join(t.TempDir(), "missing")})
+	if problems := problemsOf(t, err); len(problems) != 1 || !strings.HasPrefix(problems[0], "ACCESS_TOKEN_KEY_FILE:") {
+		t.Errorf("expected the missing file to be reported, got %v", problems)
+	}
+}
+
+func TestLoad_Validation(t *testing.T) {
+	strong := map[string]string{
+		"GO_ENV":               "production",
+		"ACCESS_TOKEN_KEY":     strings.Repeat("a", minSecretLength),
+		"REFRESH_TOKEN_KEY":    strings.Repeat("r", minSecretLength),
+		"CORS_ALLOWED_ORIGINS": "https://app.example.com",
+	}
+
+	t.Run("strong production configuration", func(t *testing.T) {
+		if _, err := load(strong); err != nil {
+			t.Errorf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("default secrets and open cors in production", func(t *testing.T) {
+		_, err := load(map[string]string{"GO_ENV": "production"})
+
+		problems := problemsOf(t, err)
+		for _, expected := range []string{"ACCESS_TOKEN_KEY must be changed", "REFRESH_TOKEN_KEY must be changed", "CORS_ALLOWED_ORIGINS must be set"} {
+			if !strings.Contains(strings.Join(problems, "\n"), expected) {
+				t.Errorf("expected %q in %v", expected, problems)
+			}
+		}
+	})
+
+	t.Run("metrics without token in production", func(t *testing.T) {
+		vars := maps.Clone(strong)
+		if _, err := load(vars); err != nil {
+			t.Errorf("expected metrics to be disabled by default, got %v", err)
+		}
+
+		vars["METRICS_PORT"] = "9090"
+		_, err := load(vars)
+		if problems := problemsOf(t, err); len(problems) != 1 || !strings.Contains(problems[0], "METRICS_TOKEN") {
+			t.Errorf("expected METRICS_TOKEN to be reported, got %v", problems)
+		}
+
+	})
+
+	t.Run("renamed pool settings", func(t *testing.T) {
+		cfg, err := load(map[string]string{"POSTGRES_MAX_OPEN_CONNS": "20", "POSTGRES_QUERY_TIMEOUT": "5s", "DB_QUERY_TIMEOUT": "10s"})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if cfg.DB_MAX_OPEN_CONNS != 20 || cfg.DB_QUERY_TIMEOUT != 10*time.Second {
+			t.Errorf("expected the old name to be read unless the new one is set, got %d and %s", cfg.DB_MAX_OPEN_CONNS, cfg.DB_QUERY_TIMEOUT)
+		}
+	})
+
+	t.Run("password hash cost", func(t *testing.T) {
+		_, err := load(map[string]string{"PASSWORD_HASH_COST": "3"})
+		if problems := problemsOf(t, err); len(problems) != 1 || !strings.Contains(problems[0], "PASSWORD_HASH_COST") {
+			t.Errorf("expected PASSWORD_HASH_COST to be reported, got %v", problems)
+		}
+	})
+
+	t.Run("database driver", func(t *testing.T) {
+		if _, err := load(map[string]string{"DB_DRIVER": "sqlite"}); err != nil {
+			t.Errorf("expected sqlite to be accepted, got %v", err)
+		}
+
+		_, err := load(map[string]string{"DB_DRIVER": "oracle"})
+		if problems := problemsOf(t, err); len(problems) != 1 || !strings.Contains(problems[0], "DB_DRIVER") {
+			t.Errorf("expected DB_DRIVER to be reported, got %v", problems)
+		}
+
+		_, err = load(map[string]string{"DB_DRIVER": "mysql", "POSTGRES_REPLICA_HOSTS": "replica-1"})
+		if problems := problemsOf(t, err); len(problems) != 1 || !strings.Contains(problems[0], "POSTGRES_REPLICA_HOSTS") {
+			t.Errorf("expected POSTGRES_REPLICA_HOSTS to be reported, got %v", problems)
+		}
+	})
+
+	t.Run("user log sinks", func(t *testing.T) {
+		cfg, err := load(map[string]string{"USER_LOG_SINKS": " database, file "})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if sinks := cfg.UserLogSinks(); len(sinks) != 2 || sinks[0] != UserLogSinkDatabase || sinks[1] != UserLogSinkFile {
+			t.Errorf("expected database and file, got %v", sinks)
+		}
+
+		_, err = load(map[string]string{"USER_LOG_SINKS": "file,kafka,file"})
+		if problems := problemsOf(t, err); len(problems) != 2 {
+			t.Errorf("expected the unknown and repeated sinks to be reported, got %v", problems)
+		}
+	})
+
+	t.Run("user log publisher", func(t *testing.T) {
+		_, err := load(map[string]string{"USER_LOG_PUBLISHER": "kafka"})
+		if problems := problemsOf(t, err); len(problems) != 1 || !strings.Contains(problems[0], "USER_LOG_PUBLISHER") {
+			t.Errorf("expected USER_LOG_PUBLISHER to be reported, got %v", problems)
+		}
+	})
+
+	t.Run("every problem is reported", func(t *testing.T) {
+		vars := map[string]string{"MODE": "verbose", "JOB_WORKERS": "0", "TRACING_SAMPLE_RATIO": "2"}
+		for key, value := range strong {
+			vars[key] = value
+		}
+		vars["REFRESH_TOKEN_KEY"] = "short"
+
+		_, err := load(vars)
+		if problems := problemsOf(t, err); len(problems) != 4 {
+			t.Errorf("expected 4 problems, got %v", problems)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+const redacted = "REDACTED"
+
+type Entry struct {
+	Name  string
+	Value string
+}
+
+// Entries lists every setting in declaration order with secrets replaced.
+// Fields tagged redact:"true" are hidden whenever set, redact:"url" only
+// hides the password of a connection URL.
+func (c *AppConfig) Entries() []Entry {
+	value := reflect.ValueOf(c).Elem()
+	fields := value.Type()
+
+	entries := make([]Entry, 0, fields.NumField())
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+		val := fmt.Sprint(value.Field(i).Interface())
+
+		switch field.Tag.Get("redact") {
+		case "true":
+			if val != "" {
+				val = redacted
+			}
+		case "url":
+			val = redactURL(val)
+		}
+
+		entries = append(entries, Entry{Name: field.Name, Value: val})
+	}
+
+	return entries
+}
+
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		// Unparsable URLs may still hold credentials.
+		return redacted
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+
+	return u.String()
+}
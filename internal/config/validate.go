@@ -0,0 +1,205 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	EnvDevelopment = "development"
+
+	// minSecretLength is the HS256 key size recommended by RFC 7518.
+	minSecretLength = 32
+)
+
+// defaultSecrets are the envDefault token keys, fine for development only.
+var defaultSecrets = []string{"secret", "refresh_secret"}
+
+// validate returns every problem with the configuration. Outside of
+// development it also refuses weak token keys, an open CORS policy and an
+// unauthenticated metrics port.
+func (c *AppConfig) validate() []string {
+	var problems []string
+	problemf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	oneOf := func(name, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			problemf("%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+		}
+	}
+	oneOf("MODE", c.MODE, "debug", "release", "test")
+	oneOf("LOG_LEVEL", strings.ToLower(c.LOG_LEVEL), "debug", "info", "warn", "error")
+	oneOf("LOG_FORMAT", c.LOG_FORMAT, "json", "text")
+	oneOf("DB_DRIVER", c.DB_DRIVER, "postgres", "sqlite", "mysql")
+	oneOf("TRACING_EXPORTER", c.TRACING_EXPORTER, "none", "otlp", "stdout", "file")
+	if c.MONGODB_READ_PREFERENCE != "" {
+		oneOf("MONGODB_READ_PREFERENCE", c.MONGODB_READ_PREFERENCE, "primary", "primaryPreferred", "secondary", "secondaryPreferred", "nearest")
+	}
+
+	if c.TRACING_SAMPLE_RATIO < 0 || c.TRACING_SAMPLE_RATIO > 1 {
+		problemf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.TRACING_SAMPLE_RATIO)
+	}
+
+	if !validPort(c.PORT) {
+		problemf("PORT must be a port number, got %q", c.PORT)
+	}
+	if c.METRICS_PORT != "" && !validPort(c.METRICS_PORT) {
+		problemf("METRICS_PORT must be a port number or empty, got %q", c.METRICS_PORT)
+	}
+	if c.METRICS_PORT == c.PORT {
+		problemf("METRICS_PORT must differ from PORT")
+	}
+
+	positive := map[string]time.Duration{
+		"ACCESS_TOKEN_TTL":       c.ACCESS_TOKEN_TTL,
+		"REFRESH_TOKEN_TTL":      c.REFRESH_TOKEN_TTL,
+		"RATE_LIMIT_WINDOW":      c.RATE_LIMIT_WINDOW,
+		"IDEMPOTENCY_TTL":        c.IDEMPOTENCY_TTL,
+		"USER_CACHE_TTL":         c.USER_CACHE_TTL,
+		"JOB_POLL_INTERVAL":      c.JOB_POLL_INTERVAL,
+		"JOB_STALE_AFTER":        c.JOB_STALE_AFTER,
+		"JOB_ARTIFACT_RETENTION": c.JOB_ARTIFACT_RETENTION,
+		"HEALTH_CHECK_TIMEOUT":   c.HEALTH_CHECK_TIMEOUT,
+
+		"MONGODB_CONNECT_TIMEOUT":          c.MONGODB_CONNECT_TIMEOUT,
+		"MONGODB_SERVER_SELECTION_TIMEOUT": c.MONGODB_SERVER_SELECTION_TIMEOUT,
+	}
+	for _, name := range sortedKeys(positive) {
+		if positive[name] <= 0 {
+			problemf("%s must be positive, got %s", name, positive[name])
+		}
+	}
+	if c.HEALTH_CACHE_TTL < 0 {
+		problemf("HEALTH_CACHE_TTL must not be negative, got %s", c.HEALTH_CACHE_TTL)
+	}
+	if c.SHUTDOWN_DELAY < 0 {
+		problemf("SHUTDOWN_DELAY must not be negative, got %s", c.SHUTDOWN_DELAY)
+	}
+
+	if c.DB_DRIVER == "sqlite" && c.SQLITE_PATH == "" {
+		problemf("SQLITE_PATH must not be empty when DB_DRIVER is sqlite")
+	}
+	if c.DB_DRIVER != "postgres" && c.POSTGRES_REPLICA_HOSTS != "" {
+		problemf("POSTGRES_REPLICA_HOSTS requires DB_DRIVER postgres")
+	}
+	if c.DB_MAX_OPEN_CONNS < 0 || c.DB_MAX_IDLE_CONNS < 0 {
+		problemf("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS must not be negative")
+	}
+	nonNegative := map[string]time.Duration{
+		"DB_CONN_MAX_LIFETIME":       c.DB_CONN_MAX_LIFETIME,
+		"DB_CONN_MAX_IDLE_TIME":      c.DB_CONN_MAX_IDLE_TIME,
+		"POSTGRES_STATEMENT_TIMEOUT": c.POSTGRES_STATEMENT_TIMEOUT,
+		"DB_QUERY_TIMEOUT":           c.DB_QUERY_TIMEOUT,
+	}
+	for _, name := range sortedKeys(nonNegative) {
+		if nonNegative[name] < 0 {
+			problemf("%s must not be negative, got %s", name, nonNegative[name])
+		}
+	}
+
+	sinks := c.UserLogSinks()
+	if len(sinks) == 0 {
+		problemf("USER_LOG_SINKS must list at least one sink")
+	}
+	for i, sink := range sinks {
+		oneOf("USER_LOG_SINKS", sink, UserLogSinkMongo, UserLogSinkDatabase, UserLogSinkFile)
+		if slices.Contains(sinks[:i], sink) {
+			problemf("USER_LOG_SINKS lists %q more than once", sink)
+		}
+	}
+	if slices.Contains(sinks, UserLogSinkFile) {
+		if c.USER_LOG_FILE_PATH == "" {
+			problemf("USER_LOG_FILE_PATH must not be empty with the file sink")
+		}
+		if c.USER_LOG_FILE_MAX_SIZE_MB < 1 || c.USER_LOG_FILE_MAX_BACKUPS < 0 {
+			problemf("USER_LOG_FILE_MAX_SIZE_MB must be at least 1 and USER_LOG_FILE_MAX_BACKUPS not negative")
+		}
+	}
+
+	oneOf("USER_LOG_PUBLISHER", c.USER_LOG_PUBLISHER, UserLogPublisherRedis, UserLogPublisherMemory, UserLogPublisherNone)
+
+	if c.MONGODB_DATABASE == "" || c.MONGODB_USER_LOG_COLLECTION == "" {
+		problemf("MONGODB_DATABASE and MONGODB_USER_LOG_COLLECTION must not be empty")
+	}
+	if c.MONGODB_MAX_POOL_SIZE != 0 && c.MONGODB_MIN_POOL_SIZE > c.MONGODB_MAX_POOL_SIZE {
+		problemf("MONGODB_MIN_POOL_SIZE must not exceed MONGODB_MAX_POOL_SIZE")
+	}
+
+	if c.JOB_WORKERS < 1 {
+		problemf("JOB_WORKERS must be at least 1, got %d", c.JOB_WORKERS)
+	}
+	if c.IDEMPOTENCY_MAX_BODY_SIZE < 1 {
+		problemf("IDEMPOTENCY_MAX_BODY_SIZE must be at least 1, got %d", c.IDEMPOTENCY_MAX_BODY_SIZE)
+	}
+	if c.PASSWORD_HASH_COST < bcrypt.MinCost || c.PASSWORD_HASH_COST > bcrypt.MaxCost {
+		problemf("PASSWORD_HASH_COST must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.PASSWORD_HASH_COST)
+	}
+	if c.JOB_MAX_ATTEMPTS < 1 {
+		problemf("JOB_MAX_ATTEMPTS must be at least 1, got %d", c.JOB_MAX_ATTEMPTS)
+	}
+	limits := map[string]int{
+		"RATE_LIMIT_AUTH":      c.RATE_LIMIT_AUTH,
+		"RATE_LIMIT_USERS":     c.RATE_LIMIT_USERS,
+		"RATE_LIMIT_USER_LOGS": c.RATE_LIMIT_USER_LOGS,
+	}
+	for _, name := range sortedKeys(limits) {
+		if limits[name] < 0 {
+			problemf("%s must not be negative, got %d", name, limits[name])
+		}
+	}
+
+	if c.GO_ENV == EnvDevelopment {
+		return problems
+	}
+
+	secrets := map[string]string{
+		"ACCESS_TOKEN_KEY":  c.ACCESS_TOKEN_KEY,
+		"REFRESH_TOKEN_KEY": c.REFRESH_TOKEN_KEY,
+	}
+	for _, name := range sortedKeys(secrets) {
+		switch secret := secrets[name]; {
+		case slices.Contains(defaultSecrets, secret):
+			problemf("%s must be changed from its default outside %s", name, EnvDevelopment)
+		case len(secret) < minSecretLength:
+			problemf("%s must have at least %d characters outside %s", name, minSecretLength, EnvDevelopment)
+		}
+	}
+	if c.ACCESS_TOKEN_KEY == c.REFRESH_TOKEN_KEY {
+		problemf("ACCESS_TOKEN_KEY and REFRESH_TOKEN_KEY must differ")
+	}
+
+	if c.METRICS_PORT != "" && c.METRICS_TOKEN == "" {
+		problemf("METRICS_TOKEN must be set outside %s, or METRICS_PORT left empty", EnvDevelopment)
+	}
+
+	origins := strings.Split(c.CORS_ALLOWED_ORIGINS, ",")
+	switch {
+	case strings.TrimSpace(c.CORS_ALLOWED_ORIGINS) == "":
+		problemf("CORS_ALLOWED_ORIGINS must be set outside %s", EnvDevelopment)
+	case c.CORS_ALLOW_CREDENTIALS && slices.Contains(origins, "*"):
+		problemf("CORS_ALLOWED_ORIGINS must list origins when CORS_ALLOW_CREDENTIALS is true, not *")
+	}
+
+	return problems
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n < 65536
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"codetest/internal/requestctx"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("context values are added to records", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "info", FormatJSON)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		ctx := requestctx.WithRequestID(context.Background(), "req-1")
+		logger.InfoContext(ctx, "hello", slog.String("key", "value"))
+
+		var record map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+			t.Fatalf("expected a JSON line, got %q", buf.String())
+		}
+
+		if record["request_id"] != "req-1" || record["key"] != "value" || record["msg"] != "hello" {
+			t.Errorf("unexpected record %v", record)
+		}
+	})
+
+	t.Run("records below the level are dropped", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&buf, "warn", FormatText)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		logger.Info("ignored")
+		if buf.Len() != 0 {
+			t.Errorf("expected no output, got %q", buf.String())
+		}
+	})
+
+	t.Run("invalid settings are rejected", func(t *testing.T) {
+		if _, err := New(&bytes.Buffer{}, "loud", FormatJSON); err == nil {
+			t.Error("expected an error for an invalid level")
+		}
+
+		if _, err := New(&bytes.Buffer{}, "info", "xml"); err == nil {
+			t.Error("expected an error for an invalid format")
+		}
+	})
+}
@@ -0,0 +1,69 @@
+// Package metrics holds the Prometheus collectors of the application, they
+// are registered on the default registry and served by NewServer.
+package metrics
+
+import (
+	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Set at build time with -ldflags "-X codetest/internal/metrics.Version=...".
+var (
+	Version = "dev"
+	Commit  = "unknown"
+)
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by route and status.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Duration of GORM queries by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation", "table"})
+
+	RedisPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_publish_failures_total",
+		Help: "Number of messages that could not be published to Redis.",
+	}, []string{"channel"})
+
+	UserLogConsumerLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "user_log_consumer_lag_seconds",
+		Help:    "Time between a user log event being created and consumed.",
+		Buckets: []float64{.001, .005, .01, .05, .1, .5, 1, 5, 10, 30, 60},
+	})
+
+	MongoInsertErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mongo_insert_errors_total",
+		Help: "Number of failed MongoDB inserts by collection.",
+	}, []string{"collection"})
+
+	UserCacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_cache_hits_total",
+		Help: "Number of user lookups served from the cache.",
+	}, []string{"key"})
+
+	UserCacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_cache_misses_total",
+		Help: "Number of user lookups that went to the database.",
+	}, []string{"key"})
+)
+
+func init() {
+	promauto.NewGauge(prometheus.GaugeOpts{
+		Name:        "app_build_info",
+		Help:        "Build information of the running binary, always 1.",
+		ConstLabels: prometheus.Labels{"version": Version, "commit": Commit, "goversion": runtime.Version()},
+	}).Set(1)
+}
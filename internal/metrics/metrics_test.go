@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/users/:id", func(c *gin.Context) { c.Status(200) })
+
+	before := testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues("GET", "/users/:id", "200"))
+
+	for _, path := range []string{"/users/1", "/users/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues("GET", "/users/:id", "200")) - before; got != 2 {
+		t.Errorf("expected 2 requests labeled by route pattern, got %v", got)
+	}
+
+	if got := testutil.ToFloat64(HTTPRequestsTotal.WithLabelValues("GET", "unmatched", "404")); got != 1 {
+		t.Errorf("expected unmatched request to be counted, got %v", got)
+	}
+}
+
+func TestServer(t *testing.T) {
+	server := NewServer(":0", "secret")
+
+	tests := []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "missing token", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", authorization: "Bearer nope", expectedStatus: http.StatusUnauthorized},
+		{name: "valid token", authorization: "Bearer secret", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			server.Handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if w.Code == http.StatusOK && !strings.Contains(w.Body.String(), "app_build_info") {
+				t.Errorf("expected build info in the metrics")
+			}
+		})
+	}
+}
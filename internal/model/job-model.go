@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type JobType string
+
+const (
+	JobTypeUserImport JobType = "user:import"
+	JobTypeUserExport JobType = "user:export"
+	JobTypeUserPurge  JobType = "user:purge"
+	JobTypeUserRehash JobType = "user:rehash"
+)
+
+func (t JobType) String() string {
+	return string(t)
+}
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+func (s JobStatus) String() string {
+	return string(s)
+}
+
+// Finished reports whether the job reached a terminal status.
+func (s JobStatus) Finished() bool {
+	return s == JobStatusSucceeded || s == JobStatusFailed || s == JobStatusCancelled
+}
+
+type JobModel struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Type            JobType    `gorm:"type:varchar(64);not null" json:"type"`
+	Status          JobStatus  `gorm:"type:varchar(32);not null;default:queued" json:"status"`
+	Payload         JSON       `gorm:"type:jsonb" json:"payload,omitempty" swaggertype:"object"`
+	Result          JSON       `gorm:"type:jsonb" json:"result,omitempty" swaggertype:"object"`
+	Error           string     `gorm:"type:text" json:"error,omitempty"`
+	Progress        int        `gorm:"not null;default:0" json:"progress"`
+	Total           int        `gorm:"not null;default:0" json:"total"`
+	InputPath       string     `gorm:"type:varchar(1024)" json:"-"`
+	ArtifactPath    string     `gorm:"type:varchar(1024)" json:"-"`
+	HasArtifact     bool       `gorm:"-" json:"has_artifact"`
+	CancelRequested bool       `gorm:"not null;default:false" json:"cancel_requested"`
+	Attempts        int        `gorm:"not null;default:0" json:"attempts"`
+	CreatedBy       string     `gorm:"type:varchar(64)" json:"created_by,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	StartedAt       *time.Time `json:"started_at,omitempty"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+// BeforeCreate assigns the ID, see UserModel.BeforeCreate.
+func (j *JobModel) BeforeCreate(*gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
+
+func (JobModel) TableName() string {
+	return "jobs"
+}
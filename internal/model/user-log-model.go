@@ -7,12 +7,19 @@ import (
 type UserLogEvent string
 
 const (
-	UserLogEventRead   UserLogEvent = "user:read"
-	UserLogEventCreate UserLogEvent = "user:created"
-	UserLogEventUpdate UserLogEvent = "user:updated"
-	UserLogEventDelete UserLogEvent = "user:deleted"
+	UserLogEventRead    UserLogEvent = "user:read"
+	UserLogEventCreate  UserLogEvent = "user:created"
+	UserLogEventUpdate  UserLogEvent = "user:updated"
+	UserLogEventDelete  UserLogEvent = "user:deleted"
+	UserLogEventRestore UserLogEvent = "user:restored"
+	UserLogEventPurge   UserLogEvent = "user:purged"
+	UserLogEventImport  UserLogEvent = "user:imported"
+	UserLogEventExport  UserLogEvent = "user:exported"
 )
 
+// UserLogActorCLI is the user_id of events recorded by the admin CLI.
+const UserLogActorCLI = "cli"
+
 func (e UserLogEvent) String() string {
 	return string(e)
 }
@@ -24,4 +31,9 @@ type UserLogModel struct {
 	CreatedAt time.Time    `json:"created_at,omitempty" bson:"created_at"`
 	UpdatedAt *time.Time   `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
 	DeletedAt *time.Time   `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+
+	// RequestID is the X-Request-ID of the request that emitted the event and
+	// TraceContext its W3C trace context, so the consumer continues the trace.
+	RequestID    string            `json:"request_id,omitempty" bson:"request_id,omitempty"`
+	TraceContext map[string]string `json:"trace_context,omitempty" bson:"trace_context,omitempty"`
 }
@@ -7,16 +7,44 @@ import (
 	"gorm.io/gorm"
 )
 
+type UserRole string
+
+const (
+	UserRoleUser  UserRole = "user"
+	UserRoleAdmin UserRole = "admin"
+)
+
+func (r UserRole) String() string {
+	return string(r)
+}
+
 type UserModel struct {
-	ID        uuid.UUID      `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()" json:"id"`
-	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
-	Email     string         `gorm:"type:varchar(255);not null;unique" json:"email"`
-	Password  string         `gorm:"type:varchar(255);not null" json:"-"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string"`
+	ID         uuid.UUID      `gorm:"type:uuid;primaryKey" json:"id"`
+	Name       string         `gorm:"type:varchar(255);not null" json:"name"`
+	Email      string         `gorm:"type:varchar(255);not null;uniqueIndex:idx_users_email_active,where:deleted_at IS NULL" json:"email"`
+	Password   string         `gorm:"type:varchar(255);not null" json:"-"`
+	Role       UserRole       `gorm:"type:varchar(32);not null;default:user" json:"role"`
+	Version    int            `gorm:"not null;default:1" json:"version"`
+	CreatedAt  time.Time      `json:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string"`
+	DisabledAt *time.Time     `json:"disabled_at,omitempty"`
+
+	// Populated only when the list is filtered by a search term.
+	SearchRank      float64 `gorm:"->;-:migration" json:"search_rank,omitempty"`
+	SearchField     string  `gorm:"->;-:migration" json:"search_field,omitempty"`
+	SearchHighlight string  `gorm:"-" json:"search_highlight,omitempty"`
 }
 
 func (UserModel) TableName() string {
 	return "users"
 }
+
+// BeforeCreate assigns the ID in the application, not every supported
+// database can generate UUIDs.
+func (u *UserModel) BeforeCreate(*gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"codetest/internal/config"
+	"codetest/internal/logger"
+	"codetest/internal/metrics"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/opentelemetry/tracing"
+)
+
+// Supported values of DB_DRIVER, named after the GORM dialects.
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+	DriverMySQL    = "mysql"
+)
+
+var (
+	singletonDBInstance *gorm.DB
+	mu                  sync.Mutex
+)
+
+type DBConnection struct {
+	*gorm.DB
+}
+
+// NewDBConnection opens the database selected by DB_DRIVER. Every driver
+// shares the pool settings, the query timeout and the instrumentation.
+func NewDBConnection(cfg *config.AppConfig) (*DBConnection, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if singletonDBInstance != nil {
+		return &DBConnection{
+			DB: singletonDBInstance,
+		}, nil
+	}
+
+	var dialector gorm.Dialector
+	switch cfg.DB_DRIVER {
+	case DriverPostgres:
+		dialector = pg.Open(postgresDSN(cfg, cfg.POSTGRES_HOST, cfg.POSTGRES_PORT))
+	case DriverSQLite:
+		dialector = sqlite.Open(sqliteDSN(cfg))
+	case DriverMySQL:
+		dialector = mysql.Open(mysqlDSN(cfg))
+	default:
+		return nil, fmt.Errorf("unsupported DB_DRIVER %q", cfg.DB_DRIVER)
+	}
+
+	db, err := gorm.Open(dialector, &gorm.Config{
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sqlDB.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.DB_MAX_OPEN_CONNS)
+	sqlDB.SetMaxIdleConns(cfg.DB_MAX_IDLE_CONNS)
+	sqlDB.SetConnMaxLifetime(cfg.DB_CONN_MAX_LIFETIME)
+	sqlDB.SetConnMaxIdleTime(cfg.DB_CONN_MAX_IDLE_TIME)
+
+	if cfg.DB_DRIVER == DriverSQLite {
+		// SQLite allows a single writer, and every connection to :memory:
+		// opens a database of its own, so it must never be recycled either.
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+
+	if cfg.DB_DRIVER == DriverPostgres {
+		if err := useReplicas(db, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := registerQueryTimeout(db); err != nil {
+		return nil, err
+	}
+
+	if err := metrics.InstrumentGorm(db, cfg.DB_DRIVER); err != nil {
+		return nil, err
+	}
+
+	// Spans keep placeholders only, bound values include emails and password hashes.
+	if err := db.Use(tracing.NewPlugin(tracing.WithoutMetrics(), tracing.WithoutQueryVariables())); err != nil {
+		return nil, err
+	}
+
+	singletonDBInstance = db
+
+	return &DBConnection{
+		DB: singletonDBInstance,
+	}, nil
+}
+
+func (db *DBConnection) Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if singletonDBInstance != nil {
+		sqlDB, err := db.DB.DB()
+		if err != nil {
+			return err
+		}
+		if err := sqlDB.Close(); err != nil {
+			return err
+		}
+		singletonDBInstance = nil
+	}
+	return nil
+}
+
+func (db *DBConnection) LogConnectionStats() {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		slog.Error("Failed to get DB stats", logger.Error(err))
+		return
+	}
+
+	stats := sqlDB.Stats()
+
+	slog.Info("DB connection pool stats",
+		slog.Int("open_connections", stats.OpenConnections),
+		slog.Int("in_use", stats.InUse),
+		slog.Int("idle", stats.Idle),
+		slog.Int64("wait_count", stats.WaitCount),
+		slog.Duration("wait_duration", stats.WaitDuration),
+		slog.Int64("max_idle_closed", stats.MaxIdleClosed),
+		slog.Int64("max_lifetime_closed", stats.MaxLifetimeClosed),
+	)
+}
+
+func (db *DBConnection) GetDBInstance() *gorm.DB {
+	return singletonDBInstance
+}
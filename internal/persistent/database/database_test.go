@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"codetest/internal/config"
+)
+
+func TestNewDBConnection_SQLite(t *testing.T) {
+	conn, err := NewDBConnection(&config.AppConfig{DB_DRIVER: DriverSQLite, SQLITE_PATH: ":memory:"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+		singletonDBInstance = nil
+	})
+
+	if err := conn.Migrate(context.Background()); err != nil {
+		t.Fatalf("expected the SQLite migrations to apply, got %v", err)
+	}
+
+	var count int64
+	if err := conn.Table("users").Count(&count).Error; err != nil {
+		t.Errorf("expected the users table to exist, got %v", err)
+	}
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	if got := sqliteDSN(&config.AppConfig{SQLITE_PATH: "data/app.db"}); !strings.HasPrefix(got, "data/app.db?") || !strings.Contains(got, "journal_mode") {
+		t.Errorf("expected a file database in WAL mode, got %q", got)
+	}
+
+	if got := sqliteDSN(&config.AppConfig{SQLITE_PATH: ":memory:"}); strings.Contains(got, "journal_mode") {
+		t.Errorf("expected no journal mode for an in-memory database, got %q", got)
+	}
+}
+
+func TestMySQLDSN(t *testing.T) {
+	got := mysqlDSN(&config.AppConfig{
+		MYSQL_USERNAME: "root",
+		MYSQL_PASSWORD: "secret",
+		MYSQL_HOST:     "localhost",
+		MYSQL_PORT:     "3306",
+		MYSQL_DB:       "codetest",
+	})
+
+	want := "root:secret@tcp(localhost:3306)/codetest?parseTime=true&charset=utf8mb4"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"codetest/database/migrations"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// NewMigrator returns a goose provider for the embedded migrations of the
+// connected driver. On Postgres every operation holds an advisory lock, so
+// replicas booting together apply each migration once while the others wait.
+// Goose has no such lock for MySQL, migrate those from a single process.
+//
+// The provider shares the connection pool, do not Close it.
+func (db *DBConnection) NewMigrator() (*goose.Provider, error) {
+	sqlDB, err := db.DB.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	driver := db.Dialector.Name()
+	fsys, err := migrations.For(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []goose.ProviderOption
+	var dialect goose.Dialect
+	switch driver {
+	case DriverPostgres:
+		locker, err := lock.NewPostgresSessionLocker()
+		if err != nil {
+			return nil, err
+		}
+		dialect = goose.DialectPostgres
+		opts = append(opts, goose.WithSessionLocker(locker))
+	case DriverSQLite:
+		dialect = goose.DialectSQLite3
+	case DriverMySQL:
+		dialect = goose.DialectMySQL
+	default:
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+
+	return goose.NewProvider(dialect, sqlDB, fsys, opts...)
+}
+
+// Migrate applies all pending migrations.
+func (db *DBConnection) Migrate(ctx context.Context) error {
+	migrator, err := db.NewMigrator()
+	if err != nil {
+		return err
+	}
+
+	results, err := migrator.Up(ctx)
+	for _, result := range results {
+		slog.InfoContext(ctx, "Applied migration",
+			slog.String("source", result.Source.Path),
+			slog.Duration("duration", result.Duration),
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := migrator.GetDBVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Database schema is up to date", slog.Int64("version", version))
+	return nil
+}
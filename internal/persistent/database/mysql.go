@@ -0,0 +1,23 @@
+package database
+
+import (
+	"net"
+
+	"codetest/internal/config"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDSN parses DATETIME columns into time.Time, both ways in UTC.
+func mysqlDSN(cfg *config.AppConfig) string {
+	mysqlCfg := mysql.NewConfig()
+	mysqlCfg.User = cfg.MYSQL_USERNAME
+	mysqlCfg.Passwd = cfg.MYSQL_PASSWORD
+	mysqlCfg.Net = "tcp"
+	mysqlCfg.Addr = net.JoinHostPort(cfg.MYSQL_HOST, cfg.MYSQL_PORT)
+	mysqlCfg.DBName = cfg.MYSQL_DB
+	mysqlCfg.ParseTime = true
+	mysqlCfg.Params = map[string]string{"charset": "utf8mb4"}
+
+	return mysqlCfg.FormatDSN()
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"codetest/internal/config"
+
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// postgresDSN builds the connection string of one server. The application name shows
+// up in pg_stat_activity, the statement timeout is enforced by the server.
+func postgresDSN(cfg *config.AppConfig, host, port string) string {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s application_name=%s",
+		host,
+		port,
+		cfg.POSTGRES_USERNAME,
+		cfg.POSTGRES_PASSWORD,
+		cfg.POSTGRES_DB,
+		cfg.POSTGRES_SSLMODE,
+		cfg.POSTGRES_APPLICATION_NAME)
+
+	if cfg.POSTGRES_STATEMENT_TIMEOUT > 0 {
+		dsn += fmt.Sprintf(" statement_timeout=%d", cfg.POSTGRES_STATEMENT_TIMEOUT.Milliseconds())
+	}
+
+	return dsn
+}
+
+// useReplicas routes reads outside of transactions to the replicas listed in
+// POSTGRES_REPLICA_HOSTS, writes and locking reads stay on the primary.
+func useReplicas(db *gorm.DB, cfg *config.AppConfig) error {
+	if cfg.POSTGRES_REPLICA_HOSTS == "" {
+		return nil
+	}
+
+	var replicas []gorm.Dialector
+	for _, hostPort := range strings.Split(cfg.POSTGRES_REPLICA_HOSTS, ",") {
+		host, port, err := net.SplitHostPort(strings.TrimSpace(hostPort))
+		if err != nil {
+			host, port = strings.TrimSpace(hostPort), cfg.POSTGRES_PORT
+		}
+
+		replicas = append(replicas, pg.Open(postgresDSN(cfg, host, port)))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas:          replicas,
+		Policy:            dbresolver.RandomPolicy{},
+		TraceResolverMode: cfg.MODE == "debug",
+	}).
+		SetMaxOpenConns(cfg.DB_MAX_OPEN_CONNS).
+		SetMaxIdleConns(cfg.DB_MAX_IDLE_CONNS).
+		SetConnMaxLifetime(cfg.DB_CONN_MAX_LIFETIME).
+		SetConnMaxIdleTime(cfg.DB_CONN_MAX_IDLE_TIME)
+
+	return db.Use(resolver)
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"codetest/internal/config"
+	"codetest/internal/requestctx"
+
+	pg "gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestPostgresDSN(t *testing.T) {
+	cfg := &config.AppConfig{
+		POSTGRES_USERNAME:         "postgres",
+		POSTGRES_PASSWORD:         "secret",
+		POSTGRES_DB:               "codetest",
+		POSTGRES_SSLMODE:          "disable",
+		POSTGRES_APPLICATION_NAME: "codetest",
+	}
+
+	got := postgresDSN(cfg, "replica-1", "5433")
+	want := "host=replica-1 port=5433 user=postgres password=secret dbname=codetest sslmode=disable application_name=codetest"
+	if got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	cfg.POSTGRES_STATEMENT_TIMEOUT = 1500 * time.Millisecond
+	if got := postgresDSN(cfg, "localhost", "5432"); !strings.HasSuffix(got, " statement_timeout=1500") {
+		t.Errorf("expected the statement timeout in milliseconds, got %q", got)
+	}
+}
+
+func TestQueryTimeout_ChainedStatements(t *testing.T) {
+	db, err := gorm.Open(pg.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if err := registerQueryTimeout(db); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Count then Find share a statement, the second one must not inherit the
+	// context the first one cancelled.
+	ctx := requestctx.WithQueryTimeout(context.Background(), time.Second)
+	query := db.WithContext(ctx).Table("users").Where("deleted_at IS NULL")
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := query.Statement.Context.Err(); err != nil {
+		t.Errorf("expected the next statement to get a live context, got %v", err)
+	}
+}
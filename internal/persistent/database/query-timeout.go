@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+
+	"codetest/internal/requestctx"
+
+	"gorm.io/gorm"
+)
+
+const queryTimeoutKey = "query_timeout"
+
+// queryTimeout is kept on the statement between the callbacks so the parent
+// context can be put back, letting a chain such as Count then Find run its
+// next statement with a fresh timeout.
+type queryTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// registerQueryTimeout bounds each statement by the timeout carried in its
+// context, see requestctx.WithQueryTimeout. Row queries are left alone as
+// their rows are read after the callbacks returned, POSTGRES_STATEMENT_TIMEOUT
+// covers them server side.
+func registerQueryTimeout(db *gorm.DB) error {
+	before := func(db *gorm.DB) {
+		timeout := requestctx.QueryTimeout(db.Statement.Context)
+		if timeout <= 0 {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(db.Statement.Context, timeout)
+		db.InstanceSet(queryTimeoutKey, queryTimeout{parent: db.Statement.Context, cancel: cancel})
+		db.Statement.Context = ctx
+	}
+	after := func(db *gorm.DB) {
+		if value, ok := db.InstanceGet(queryTimeoutKey); ok {
+			bound := value.(queryTimeout)
+			bound.cancel()
+			db.Statement.Context = bound.parent
+		}
+	}
+
+	callback := db.Callback()
+	for _, err := range []error{
+		callback.Create().Before("gorm:create").Register("query_timeout:before_create", before),
+		callback.Create().After("gorm:create").Register("query_timeout:after_create", after),
+		callback.Query().Before("gorm:query").Register("query_timeout:before_query", before),
+		callback.Query().After("gorm:query").Register("query_timeout:after_query", after),
+		callback.Update().Before("gorm:update").Register("query_timeout:before_update", before),
+		callback.Update().After("gorm:update").Register("query_timeout:after_update", after),
+		callback.Delete().Before("gorm:delete").Register("query_timeout:before_delete", before),
+		callback.Delete().After("gorm:delete").Register("query_timeout:after_delete", after),
+		callback.Raw().Before("gorm:raw").Register("query_timeout:before_raw", before),
+		callback.Raw().After("gorm:raw").Register("query_timeout:after_raw", after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
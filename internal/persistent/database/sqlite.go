@@ -0,0 +1,21 @@
+package database
+
+import (
+	"net/url"
+
+	"codetest/internal/config"
+)
+
+// sqliteDSN opens SQLITE_PATH with foreign keys enforced and a busy timeout,
+// so a writer waits for the lock instead of failing right away. A file
+// database also switches to WAL to let reads proceed during writes.
+func sqliteDSN(cfg *config.AppConfig) string {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	if cfg.SQLITE_PATH != ":memory:" {
+		pragmas.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	return cfg.SQLITE_PATH + "?" + pragmas.Encode()
+}
@@ -2,10 +2,17 @@ package mongo
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"sync"
 
+	"codetest/internal/config"
+	"codetest/internal/telemetry"
+
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
 var (
@@ -17,7 +24,7 @@ type DBConnection struct {
 	*mongo.Client
 }
 
-func NewMongoDBConnection(uri string) (*DBConnection, error) {
+func NewMongoDBConnection(cfg *config.AppConfig) (*DBConnection, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
@@ -27,11 +34,25 @@ func NewMongoDBConnection(uri string) (*DBConnection, error) {
 		}, nil
 	}
 
-	client, err := mongo.Connect(nil, options.Client().ApplyURI(uri))
+	clientOptions, err := clientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := mongo.Connect(clientOptions)
 	if err != nil {
 		return nil, err
 	}
 
+	// Connect is lazy, ping so an unreachable server fails the startup.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MONGODB_SERVER_SELECTION_TIMEOUT)
+	defer cancel()
+
+	if err := client.Ping(ctx, clientOptions.ReadPreference); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping mongodb: %w", err)
+	}
+
 	singletonDBInstance = client
 
 	return &DBConnection{
@@ -39,6 +60,51 @@ func NewMongoDBConnection(uri string) (*DBConnection, error) {
 	}, nil
 }
 
+// clientOptions applies the pool and timeout settings over the URI. Write
+// concern and read preference are only overridden when configured.
+func clientOptions(cfg *config.AppConfig) (*options.ClientOptions, error) {
+	clientOptions := options.Client().
+		ApplyURI(cfg.MONGODB_URI).
+		SetMonitor(telemetry.NewMongoMonitor()).
+		SetMaxPoolSize(cfg.MONGODB_MAX_POOL_SIZE).
+		SetMinPoolSize(cfg.MONGODB_MIN_POOL_SIZE).
+		SetConnectTimeout(cfg.MONGODB_CONNECT_TIMEOUT).
+		SetServerSelectionTimeout(cfg.MONGODB_SERVER_SELECTION_TIMEOUT)
+
+	if cfg.MONGODB_WRITE_CONCERN != "" {
+		clientOptions.SetWriteConcern(parseWriteConcern(cfg.MONGODB_WRITE_CONCERN))
+	}
+
+	if cfg.MONGODB_READ_PREFERENCE != "" {
+		mode, err := readpref.ModeFromString(cfg.MONGODB_READ_PREFERENCE)
+		if err != nil {
+			return nil, err
+		}
+
+		readPref, err := readpref.New(mode)
+		if err != nil {
+			return nil, err
+		}
+		clientOptions.SetReadPreference(readPref)
+	}
+
+	return clientOptions, nil
+}
+
+// parseWriteConcern maps "majority", a number of nodes or a tag set name to
+// a write concern.
+func parseWriteConcern(value string) *writeconcern.WriteConcern {
+	if value == "majority" {
+		return writeconcern.Majority()
+	}
+
+	if nodes, err := strconv.Atoi(value); err == nil {
+		return &writeconcern.WriteConcern{W: nodes}
+	}
+
+	return writeconcern.Custom(value)
+}
+
 func (db *DBConnection) Close(ctx context.Context) error {
 	mu.Lock()
 	defer mu.Unlock()
@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"codetest/internal/config"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func TestClientOptions(t *testing.T) {
+	cfg := &config.AppConfig{
+		MONGODB_URI:                      "mongodb://localhost:27017/?w=majority",
+		MONGODB_MAX_POOL_SIZE:            20,
+		MONGODB_MIN_POOL_SIZE:            2,
+		MONGODB_SERVER_SELECTION_TIMEOUT: 3 * time.Second,
+	}
+
+	t.Run("uri settings are kept unless configured", func(t *testing.T) {
+		clientOptions, err := clientOptions(cfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if *clientOptions.MaxPoolSize != 20 || *clientOptions.MinPoolSize != 2 {
+			t.Errorf("unexpected pool sizes %d and %d", *clientOptions.MaxPoolSize, *clientOptions.MinPoolSize)
+		}
+
+		if clientOptions.WriteConcern.W != "majority" {
+			t.Errorf("expected the write concern of the uri, got %v", clientOptions.WriteConcern.W)
+		}
+	})
+
+	t.Run("configured values win", func(t *testing.T) {
+		cfg := *cfg
+		cfg.MONGODB_WRITE_CONCERN = "1"
+		cfg.MONGODB_READ_PREFERENCE = "secondaryPreferred"
+
+		clientOptions, err := clientOptions(&cfg)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if clientOptions.WriteConcern.W != 1 {
+			t.Errorf("expected w=1, got %v", clientOptions.WriteConcern.W)
+		}
+
+		if clientOptions.ReadPreference.Mode() != readpref.SecondaryPreferredMode {
+			t.Errorf("expected secondaryPreferred, got %s", clientOptions.ReadPreference.Mode())
+		}
+	})
+
+	t.Run("invalid read preference", func(t *testing.T) {
+		cfg := *cfg
+		cfg.MONGODB_READ_PREFERENCE = "closest"
+
+		if _, err := clientOptions(&cfg); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
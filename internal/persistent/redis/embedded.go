@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"log/slog"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewEmbeddedRedisConnection starts an in-process Redis server and connects
+// to it, standing in for the real one in demo mode. It covers the user log
+// channel, the rate limiter and idempotency keys; data is lost on Close.
+func NewEmbeddedRedisConnection() (*RedisConnection, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	server, err := miniredis.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	// Commands carry tokens and cached users, spans keep their name only.
+	if err := redisotel.InstrumentTracing(client, redisotel.WithDBStatement(false)); err != nil {
+		server.Close()
+		return nil, err
+	}
+
+	if singletonRedisInstance != nil {
+		_ = singletonRedisInstance.Close()
+	}
+	singletonRedisInstance = client
+	slog.Info("Embedded Redis started", slog.String("addr", server.Addr()))
+
+	return &RedisConnection{
+		Client:   client,
+		embedded: server,
+	}, nil
+}
@@ -2,9 +2,11 @@ package redis
 
 import (
 	"codetest/internal/config"
-	"log"
+	"log/slog"
 	"sync"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -15,6 +17,9 @@ var (
 
 type RedisConnection struct {
 	*redis.Client
+
+	// embedded is the in-process server of NewEmbeddedRedisConnection.
+	embedded *miniredis.Miniredis
 }
 
 func NewRedisConnection(cfg *config.AppConfig) (*RedisConnection, error) {
@@ -34,6 +39,10 @@ func NewRedisConnection(cfg *config.AppConfig) (*RedisConnection, error) {
 	}
 
 	client := redis.NewClient(options)
+	// Commands carry tokens and cached users, spans keep their name only.
+	if err := redisotel.InstrumentTracing(client, redisotel.WithDBStatement(false)); err != nil {
+		return nil, err
+	}
 
 	singletonRedisInstance = client
 
@@ -47,12 +56,16 @@ func (r *RedisConnection) Close() error {
 	defer mu.Unlock()
 
 	if singletonRedisInstance != nil {
-		log.Println("Closing Redis connection...")
+		slog.Info("Closing Redis connection")
 		if err := singletonRedisInstance.Close(); err != nil {
 			return err
 		}
 		singletonRedisInstance = nil
-		log.Println("Redis connection closed successfully")
+		slog.Info("Redis connection closed")
+	}
+
+	if r.embedded != nil {
+		r.embedded.Close()
 	}
 	return nil
 }
@@ -0,0 +1,10 @@
+package portrepository
+
+import "errors"
+
+var (
+	ErrNotFound        = errors.New("record not found")
+	ErrAlreadyExists   = errors.New("record already exists")
+	ErrVersionConflict = errors.New("record was modified by another request")
+	ErrNotSupported    = errors.New("operation not supported by this backend")
+)
@@ -0,0 +1,25 @@
+package portrepository
+
+import (
+	"context"
+	"time"
+
+	"codetest/internal/model"
+)
+
+type JobRepository interface {
+	Create(ctx context.Context, job *model.JobModel) error
+	GetOneBy(ctx context.Context, column, value string) (*model.JobModel, error)
+	// ClaimNext marks the oldest queued job, or a running job whose worker
+	// stopped heartbeating before staleBefore, as running and returns it. It
+	// returns nil when there is nothing to do.
+	ClaimNext(ctx context.Context, staleBefore time.Time) (*model.JobModel, error)
+	// Heartbeat stores the progress of a running job and reports whether its
+	// cancellation was requested.
+	Heartbeat(ctx context.Context, job *model.JobModel) (cancelRequested bool, err error)
+	Finish(ctx context.Context, job *model.JobModel) error
+	// Requeue puts a running job back in the queue, giving back the attempt
+	// its claim counted since the job was interrupted rather than failing.
+	Requeue(ctx context.Context, job *model.JobModel) error
+	RequestCancel(ctx context.Context, job *model.JobModel) error
+}
@@ -2,6 +2,7 @@ package portrepository
 
 import (
 	"context"
+	"time"
 
 	"codetest/internal/adapter/api/dto"
 	"codetest/internal/model"
@@ -9,8 +10,15 @@ import (
 
 type UserRepository interface {
 	Create(ctx context.Context, user *model.UserModel) error
+	CreateMany(ctx context.Context, users []*model.UserModel) error
+	ExistingEmails(ctx context.Context, emails []string) ([]string, error)
 	Find(ctx context.Context, request *dto.QueryUserRequest) ([]*model.UserModel, int64, error)
+	Stream(ctx context.Context, request *dto.QueryUserRequest, fn func(user *model.UserModel) error) error
 	GetOneBy(ctx context.Context, column, value string) (*model.UserModel, error)
 	Update(ctx context.Context, user *model.UserModel) error
+	UpdateColumnsBy(ctx context.Context, column, value string, values map[string]interface{}) error
 	DeleteOneBy(ctx context.Context, column, value string) error
+	RestoreOneBy(ctx context.Context, column, value string) error
+	PurgeOneBy(ctx context.Context, column, value string) error
+	PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error)
 }
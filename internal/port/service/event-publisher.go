@@ -0,0 +1,12 @@
+package portservice
+
+import (
+	"context"
+
+	"codetest/internal/model"
+)
+
+// EventPublisher delivers user log events to the consumer storing them.
+type EventPublisher interface {
+	Publish(ctx context.Context, userLog *model.UserLogModel) error
+}
@@ -0,0 +1,16 @@
+package portservice
+
+import (
+	"context"
+
+	"codetest/internal/adapter/api/dto"
+)
+
+type HealthService interface {
+	// Ready checks every dependency and reports whether the service can take
+	// traffic.
+	Ready(ctx context.Context) *dto.HealthReport
+	// SetShuttingDown makes Ready fail from now on, so load balancers stop
+	// routing traffic before the server stops.
+	SetShuttingDown()
+}
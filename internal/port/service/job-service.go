@@ -0,0 +1,27 @@
+package portservice
+
+import (
+	"context"
+	"io"
+
+	"codetest/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type JobService interface {
+	// Enqueue stores a new job. input, when not nil, is saved next to the job
+	// artifacts so the worker can read it later.
+	Enqueue(ctx context.Context, jobType model.JobType, payload interface{}, input io.Reader, createdBy string) (*model.JobModel, error)
+	GetOneByID(ctx context.Context, id uuid.UUID) (*model.JobModel, error)
+	Cancel(ctx context.Context, id uuid.UUID) (*model.JobModel, error)
+}
+
+// JobProgressFunc reports how many of total units of work a job has done.
+type JobProgressFunc func(done, total int)
+
+// JobProcessor runs the jobs of one type. The returned result is stored as
+// JSON on the job, artifacts are written to job.ArtifactPath.
+type JobProcessor interface {
+	Process(ctx context.Context, job *model.JobModel, progress JobProgressFunc) (result interface{}, err error)
+}
@@ -2,6 +2,8 @@ package portservice
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"codetest/internal/adapter/api/dto"
 	"codetest/internal/model"
@@ -11,9 +13,20 @@ import (
 
 type UserService interface {
 	Create(ctx context.Context, request *dto.CreateUserRequest) error
+	CreateMany(ctx context.Context, requests []*dto.CreateUserRequest) (int, error)
+	Import(ctx context.Context, reader io.Reader, fileName, format string, dryRun bool) (*dto.ImportUserReport, error)
+	Export(ctx context.Context, request *dto.ExportUserRequest, writer io.Writer) (int, error)
 	Find(ctx context.Context, request *dto.QueryUserRequest) ([]*model.UserModel, int64, error)
 	GetOneByID(ctx context.Context, id uuid.UUID) (*model.UserModel, error)
 	GetOneByEmail(ctx context.Context, email string) (*model.UserModel, error)
-	Update(ctx context.Context, id uuid.UUID, request *dto.UpdateUserRequest) error
+	Update(ctx context.Context, id uuid.UUID, version int, request *dto.UpdateUserRequest) (*model.UserModel, error)
+	SetRole(ctx context.Context, id uuid.UUID, role model.UserRole) error
+	ResetPassword(ctx context.Context, id uuid.UUID, password string) error
+	UpgradePasswordHash(ctx context.Context, user *model.UserModel, password string) error
+	CountOutdatedPasswordHashes(ctx context.Context) (int64, int64, error)
+	Disable(ctx context.Context, id uuid.UUID) error
 	DeleteOneByID(ctx context.Context, id uuid.UUID) error
+	RestoreOneByID(ctx context.Context, id uuid.UUID) error
+	PurgeOneByID(ctx context.Context, id uuid.UUID) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,260 @@
+// Package query implements the filter and sort language accepted by list
+// endpoints, e.g.
+//
+//	?filter[email][ilike]=doe&filter[created_at][gte]=2025-01-01&sort=-created_at,name
+//
+// Requests are parsed once against a whitelist (Schema) into a Query that the
+// repository adapters translate into their own query builders.
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Operator string
+
+const (
+	OpEq    Operator = "eq"
+	OpNe    Operator = "ne"
+	OpLike  Operator = "like"
+	OpIlike Operator = "ilike"
+	OpGt    Operator = "gt"
+	OpGte   Operator = "gte"
+	OpLt    Operator = "lt"
+	OpLte   Operator = "lte"
+	OpIn    Operator = "in"
+)
+
+type FieldType int
+
+const (
+	TypeString FieldType = iota
+	TypeTime
+	TypeUUID
+)
+
+const (
+	maxValueLength = 250
+	maxInValues    = 100
+	maxSortFields  = 5
+)
+
+var (
+	StringOperators = []Operator{OpEq, OpNe, OpLike, OpIlike, OpIn}
+	EnumOperators   = []Operator{OpEq, OpNe, OpIn}
+	TimeOperators   = []Operator{OpEq, OpGt, OpGte, OpLt, OpLte}
+)
+
+// Field describes a filterable and/or sortable field. Column is the name used
+// by the storage backend and is never taken from user input.
+type Field struct {
+	Column    string
+	Type      FieldType
+	Operators []Operator
+	Sortable  bool
+}
+
+// Schema maps the public field name to its definition.
+type Schema map[string]Field
+
+// Condition is a single filter expression. Value holds a string, a time.Time
+// or, for OpIn, a []string.
+type Condition struct {
+	Field    string
+	Column   string
+	Operator Operator
+	Value    interface{}
+}
+
+type Sort struct {
+	Field  string
+	Column string
+	Desc   bool
+}
+
+type Query struct {
+	Conditions []Condition
+	Sorts      []Sort
+}
+
+// Errors maps the offending query parameter to a human readable message.
+type Errors map[string]string
+
+func (e Errors) Error() string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	messages := make([]string, 0, len(keys))
+	for _, k := range keys {
+		messages = append(messages, e[k])
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+var filterKeyPattern = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// Parse extracts the filter[...] and sort parameters from values. Any other
+// parameter is ignored.
+func Parse(schema Schema, values url.Values) (*Query, error) {
+	q := &Query{}
+	errs := Errors{}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key == "filter" || strings.HasPrefix(key, "filter[") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		matches := filterKeyPattern.FindStringSubmatch(key)
+		if matches == nil {
+			errs[key] = key + " is not a valid filter, expected filter[field][operator]"
+			continue
+		}
+
+		name, op := matches[1], Operator(matches[2])
+		if op == "" {
+			op = OpEq
+		}
+
+		field, ok := schema[name]
+		if !ok || len(field.Operators) == 0 {
+			errs[key] = name + " is not a filterable field"
+			continue
+		}
+
+		if !field.supports(op) {
+			errs[key] = fmt.Sprintf("%s does not support the %s operator", name, op)
+			continue
+		}
+
+		for _, raw := range values[key] {
+			value, err := field.parseValue(op, raw)
+			if err != nil {
+				errs[key] = name + " " + err.Error()
+				break
+			}
+
+			q.Conditions = append(q.Conditions, Condition{
+				Field:    name,
+				Column:   field.Column,
+				Operator: op,
+				Value:    value,
+			})
+		}
+	}
+
+	if raw := strings.TrimSpace(values.Get("sort")); raw != "" {
+		sorts, err := parseSort(schema, raw)
+		if err != nil {
+			errs["sort"] = err.Error()
+		}
+		q.Sorts = sorts
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return q, nil
+}
+
+func parseSort(schema Schema, raw string) ([]Sort, error) {
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxSortFields {
+		return nil, fmt.Errorf("sort must have at most %d fields", maxSortFields)
+	}
+
+	sorts := make([]Sort, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		desc := strings.HasPrefix(part, "-")
+		name := strings.TrimPrefix(strings.TrimPrefix(part, "-"), "+")
+
+		field, ok := schema[name]
+		if !ok || !field.Sortable {
+			return nil, fmt.Errorf("%q is not a sortable field", name)
+		}
+
+		if seen[name] {
+			return nil, fmt.Errorf("%s is sorted more than once", name)
+		}
+		seen[name] = true
+
+		sorts = append(sorts, Sort{Field: name, Column: field.Column, Desc: desc})
+	}
+
+	return sorts, nil
+}
+
+func (f Field) supports(op Operator) bool {
+	for _, allowed := range f.Operators {
+		if allowed == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Field) parseValue(op Operator, raw string) (interface{}, error) {
+	if op == OpIn {
+		items := strings.Split(raw, ",")
+		if len(items) > maxInValues {
+			return nil, fmt.Errorf("must have at most %d values", maxInValues)
+		}
+
+		values := make([]string, 0, len(items))
+		for _, item := range items {
+			value, err := f.parseScalar(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value.(string))
+		}
+		return values, nil
+	}
+
+	return f.parseScalar(raw)
+}
+
+func (f Field) parseScalar(raw string) (interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("must not be empty")
+	}
+
+	if len(raw) > maxValueLength {
+		return nil, fmt.Errorf("must be at most %d characters long", maxValueLength)
+	}
+
+	switch f.Type {
+	case TypeTime:
+		if t, err := time.Parse(time.RFC3339, raw); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse(time.DateOnly, raw); err == nil {
+			return t, nil
+		}
+		return nil, fmt.Errorf("must be a RFC 3339 timestamp or a YYYY-MM-DD date")
+	case TypeUUID:
+		if _, err := uuid.Parse(raw); err != nil {
+			return nil, fmt.Errorf("must be a valid UUID")
+		}
+		return raw, nil
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+var testSchema = Schema{
+	"id":         {Column: "id", Type: TypeUUID, Operators: []Operator{OpEq, OpIn}},
+	"email":      {Column: "email", Type: TypeString, Operators: StringOperators, Sortable: true},
+	"created_at": {Column: "created_at", Type: TypeTime, Operators: TimeOperators, Sortable: true},
+	"password":   {Column: "password", Type: TypeString},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedError  []string
+		expectedConds  int
+		expectedSorts  []Sort
+		validateResult func(t *testing.T, q *Query)
+	}{
+		{
+			name:          "empty query",
+			query:         "page=1&page_size=10",
+			expectedConds: 0,
+		},
+		{
+			name:          "operator defaults to eq",
+			query:         "filter[email]=john@doe.com",
+			expectedConds: 1,
+			validateResult: func(t *testing.T, q *Query) {
+				if q.Conditions[0].Operator != OpEq {
+					t.Errorf("expected eq operator, got %s", q.Conditions[0].Operator)
+				}
+			},
+		},
+		{
+			name:          "time values are parsed",
+			query:         "filter[created_at][gte]=2025-01-01&filter[created_at][lt]=2025-02-01T10:00:00Z",
+			expectedConds: 2,
+			validateResult: func(t *testing.T, q *Query) {
+				for _, cond := range q.Conditions {
+					if _, ok := cond.Value.(time.Time); !ok {
+						t.Errorf("expected time.Time value, got %T", cond.Value)
+					}
+				}
+			},
+		},
+		{
+			name:          "in values are split",
+			query:         "filter[email][in]=a@doe.com,b@doe.com",
+			expectedConds: 1,
+			validateResult: func(t *testing.T, q *Query) {
+				values, ok := q.Conditions[0].Value.([]string)
+				if !ok || len(values) != 2 {
+					t.Errorf("expected two values, got %v", q.Conditions[0].Value)
+				}
+			},
+		},
+		{
+			name:          "sort with direction",
+			query:         "sort=-created_at,email",
+			expectedSorts: []Sort{{Field: "created_at", Column: "created_at", Desc: true}, {Field: "email", Column: "email"}},
+		},
+		{
+			name:          "unknown field is rejected",
+			query:         "filter[role][eq]=admin",
+			expectedError: []string{"filter[role][eq]"},
+		},
+		{
+			name:          "field without operators is rejected",
+			query:         "filter[password][eq]=secret",
+			expectedError: []string{"filter[password][eq]"},
+		},
+		{
+			name:          "unsupported operator is rejected",
+			query:         "filter[email][gt]=a",
+			expectedError: []string{"filter[email][gt]"},
+		},
+		{
+			name:          "malformed filter key is rejected",
+			query:         "filter[email=a",
+			expectedError: []string{"filter[email"},
+		},
+		{
+			name:          "invalid values are rejected",
+			query:         "filter[created_at][gte]=yesterday&filter[id]=1",
+			expectedError: []string{"filter[created_at][gte]", "filter[id]"},
+		},
+		{
+			name:          "unsortable field is rejected",
+			query:         "sort=id",
+			expectedError: []string{"sort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("invalid test query: %v", err)
+			}
+
+			q, err := Parse(testSchema, values)
+
+			if len(tt.expectedError) > 0 {
+				errs, ok := err.(Errors)
+				if !ok {
+					t.Fatalf("expected Errors, got %v", err)
+				}
+
+				for _, key := range tt.expectedError {
+					if _, ok := errs[key]; !ok {
+						t.Errorf("expected error for %s, got %v", key, errs)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if len(q.Conditions) != tt.expectedConds {
+				t.Errorf("expected %d conditions, got %d", tt.expectedConds, len(q.Conditions))
+			}
+
+			if len(q.Sorts) != len(tt.expectedSorts) {
+				t.Fatalf("expected %d sorts, got %d", len(tt.expectedSorts), len(q.Sorts))
+			}
+			for i, s := range tt.expectedSorts {
+				if q.Sorts[i] != s {
+					t.Errorf("expected sort %v, got %v", s, q.Sorts[i])
+				}
+			}
+
+			if tt.validateResult != nil {
+				tt.validateResult(t, q)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Package requestctx carries request scoped values through a context.Context.
+package requestctx
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	requestIDKey    struct{}
+	queryTimeoutKey struct{}
+	actorKey        struct{}
+	jobIDKey        struct{}
+	primaryReadKey  struct{}
+)
+
+// WithRequestID returns a copy of ctx holding the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithQueryTimeout returns a copy of ctx bounding each database query made
+// with it to timeout. Zero disables the bound.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// QueryTimeout returns the query timeout stored in ctx, or zero.
+func QueryTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(queryTimeoutKey{}).(time.Duration)
+	return timeout
+}
+
+// WithActor returns a copy of ctx acting on behalf of actor, the ID of the
+// authenticated user or model.UserLogActorCLI. Audit events name it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the actor stored in ctx, or an empty string.
+func Actor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// WithJobID returns a copy of ctx running the background job jobID.
+func WithJobID(ctx context.Context, jobID string) context.Context {
+	return context.WithValue(ctx, jobIDKey{}, jobID)
+}
+
+// JobID returns the background job ID stored in ctx, or an empty string.
+func JobID(ctx context.Context) string {
+	jobID, _ := ctx.Value(jobIDKey{}).(string)
+	return jobID
+}
+
+// WithPrimaryRead returns a copy of ctx whose database reads go to the
+// primary rather than a replica, for reads that must see the latest write.
+func WithPrimaryRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadKey{}, true)
+}
+
+// PrimaryRead reports whether ctx asks for reads from the primary.
+func PrimaryRead(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadKey{}).(bool)
+	return primary
+}
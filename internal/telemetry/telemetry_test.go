@@ -0,0 +1,32 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestInjectExtract(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: ExporterNone}); err != nil {
+		t.Fatalf("failed to set up telemetry: %v", err)
+	}
+
+	if carrier := Inject(context.Background()); carrier != nil {
+		t.Errorf("expected no carrier without a span, got %v", carrier)
+	}
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "publish")
+	defer span.End()
+
+	carrier := Inject(ctx)
+	if carrier["traceparent"] == "" {
+		t.Fatalf("expected a traceparent, got %v", carrier)
+	}
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), carrier))
+	if extracted.TraceID() != span.SpanContext().TraceID() || !extracted.IsRemote() {
+		t.Errorf("expected the remote trace %s, got %s", span.SpanContext().TraceID(), extracted.TraceID())
+	}
+}
@@ -14,6 +14,7 @@ import (
 	model "codetest/internal/model"
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -56,6 +57,20 @@ func (mr *MockUserRepositoryMockRecorder) Create(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), ctx, user)
 }
 
+// CreateMany mocks base method.
+func (m *MockUserRepository) CreateMany(ctx context.Context, users []*model.UserModel) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", ctx, users)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockUserRepositoryMockRecorder) CreateMany(ctx, users any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockUserRepository)(nil).CreateMany), ctx, users)
+}
+
 // DeleteOneBy mocks base method.
 func (m *MockUserRepository) DeleteOneBy(ctx context.Context, column, value string) error {
 	m.ctrl.T.Helper()
@@ -70,6 +85,21 @@ func (mr *MockUserRepositoryMockRecorder) DeleteOneBy(ctx, column, value any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOneBy", reflect.TypeOf((*MockUserRepository)(nil).DeleteOneBy), ctx, column, value)
 }
 
+// ExistingEmails mocks base method.
+func (m *MockUserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExistingEmails", ctx, emails)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExistingEmails indicates an expected call of ExistingEmails.
+func (mr *MockUserRepositoryMockRecorder) ExistingEmails(ctx, emails any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistingEmails", reflect.TypeOf((*MockUserRepository)(nil).ExistingEmails), ctx, emails)
+}
+
 // Find mocks base method.
 func (m *MockUserRepository) Find(ctx context.Context, request *dto.QueryUserRequest) ([]*model.UserModel, int64, error) {
 	m.ctrl.T.Helper()
@@ -101,6 +131,63 @@ func (mr *MockUserRepositoryMockRecorder) GetOneBy(ctx, column, value any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneBy", reflect.TypeOf((*MockUserRepository)(nil).GetOneBy), ctx, column, value)
 }
 
+// PurgeDeletedBefore mocks base method.
+func (m *MockUserRepository) PurgeDeletedBefore(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedBefore", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedBefore indicates an expected call of PurgeDeletedBefore.
+func (mr *MockUserRepositoryMockRecorder) PurgeDeletedBefore(ctx, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedBefore", reflect.TypeOf((*MockUserRepository)(nil).PurgeDeletedBefore), ctx, before)
+}
+
+// PurgeOneBy mocks base method.
+func (m *MockUserRepository) PurgeOneBy(ctx context.Context, column, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeOneBy", ctx, column, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeOneBy indicates an expected call of PurgeOneBy.
+func (mr *MockUserRepositoryMockRecorder) PurgeOneBy(ctx, column, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeOneBy", reflect.TypeOf((*MockUserRepository)(nil).PurgeOneBy), ctx, column, value)
+}
+
+// RestoreOneBy mocks base method.
+func (m *MockUserRepository) RestoreOneBy(ctx context.Context, column, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreOneBy", ctx, column, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreOneBy indicates an expected call of RestoreOneBy.
+func (mr *MockUserRepositoryMockRecorder) RestoreOneBy(ctx, column, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreOneBy", reflect.TypeOf((*MockUserRepository)(nil).RestoreOneBy), ctx, column, value)
+}
+
+// Stream mocks base method.
+func (m *MockUserRepository) Stream(ctx context.Context, request *dto.QueryUserRequest, fn func(*model.UserModel) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, request, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockUserRepositoryMockRecorder) Stream(ctx, request, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockUserRepository)(nil).Stream), ctx, request, fn)
+}
+
 // Update mocks base method.
 func (m *MockUserRepository) Update(ctx context.Context, user *model.UserModel) error {
 	m.ctrl.T.Helper()
@@ -114,3 +201,17 @@ func (mr *MockUserRepositoryMockRecorder) Update(ctx, user any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepository)(nil).Update), ctx, user)
 }
+
+// UpdateColumnsBy mocks base method.
+func (m *MockUserRepository) UpdateColumnsBy(ctx context.Context, column, value string, values map[string]any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateColumnsBy", ctx, column, value, values)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateColumnsBy indicates an expected call of UpdateColumnsBy.
+func (mr *MockUserRepositoryMockRecorder) UpdateColumnsBy(ctx, column, value, values any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateColumnsBy", reflect.TypeOf((*MockUserRepository)(nil).UpdateColumnsBy), ctx, column, value, values)
+}